@@ -0,0 +1,131 @@
+package inreq
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// BodyFields is the set of RFC 6901 JSON pointers of the fields present in a JSON body, like "/name",
+// "/address" and "/address/number". Fields sent as null are also present.
+// A field of this type inside the body struct is filled after the body is decoded, and it is also available to
+// operations using [DecodeContext.BodyFields].
+type BodyFields map[string]bool
+
+// Has returns whether the JSON pointer was present in the body.
+func (b BodyFields) Has(pointer string) bool {
+	return b[pointer]
+}
+
+// Pointers returns the sorted list of JSON pointers present in the body.
+func (b BodyFields) Pointers() []string {
+	ret := make([]string, 0, len(b))
+	for pointer := range b {
+		ret = append(ret, pointer)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// fieldPointer returns the JSON pointer of the object member name inside the parent pointer. Like encoding/json,
+// if the name is not present in the body, a key matching it case-insensitively is used.
+func (b BodyFields) fieldPointer(parent string, name string) string {
+	pointer := parent + JSONPointer(name)
+	if b == nil || b[pointer] {
+		return pointer
+	}
+	for key := range b {
+		if len(key) == len(pointer) && strings.EqualFold(key, pointer) && strings.HasPrefix(key, parent+"/") {
+			return key
+		}
+	}
+	return pointer
+}
+
+var bodyFieldsType = reflect.TypeOf(BodyFields(nil))
+
+// JSONPointer returns the RFC 6901 JSON pointer of the reference tokens, escaping "~" and "/" in them, like
+// "/address/number" for "address" and "number". No tokens return "", which points to the whole document.
+func JSONPointer(tokens ...string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteString("/")
+		jsonPointerEscaper.WriteString(&b, token)
+	}
+	return b.String()
+}
+
+var jsonPointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// jsonBodyRecorder is implemented by the decode context to record the JSON body for BodyFields.
+type jsonBodyRecorder interface {
+	// recordJSONBody returns whether the JSON body decoded into data must be recorded.
+	recordJSONBody(data any) bool
+	setJSONBody(data []byte)
+}
+
+// hasBodyFieldsField returns whether the struct pointed by data has a BodyFields field.
+func hasBodyFieldsField(data any) bool {
+	typ := reflect.TypeOf(data)
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).Type == bodyFieldsType {
+			return true
+		}
+	}
+	return false
+}
+
+// parseBodyFields returns the JSON pointers present in the first JSON value of the data.
+func parseBodyFields(data []byte) BodyFields {
+	var value any
+	if err := json.NewDecoder(bytes.NewReader(data)).Decode(&value); err != nil {
+		return nil
+	}
+	ret := BodyFields{}
+	addBodyFields(ret, "", value)
+	return ret
+}
+
+func addBodyFields(fields BodyFields, prefix string, value any) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, item := range v {
+			pointer := prefix + JSONPointer(key)
+			fields[pointer] = true
+			addBodyFields(fields, pointer, item)
+		}
+	case []any:
+		for i, item := range v {
+			pointer := prefix + "/" + strconv.Itoa(i)
+			fields[pointer] = true
+			addBodyFields(fields, pointer, item)
+		}
+	}
+}
+
+// setBodyFields sets the BodyFields fields of the body struct.
+func setBodyFields(ctx DecodeContext, field reflect.Value) {
+	for field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return
+		}
+		field = field.Elem()
+	}
+	if field.Kind() != reflect.Struct {
+		return
+	}
+	for i := 0; i < field.NumField(); i++ {
+		if field.Type().Field(i).Type == bodyFieldsType && field.Field(i).CanSet() {
+			field.Field(i).Set(reflect.ValueOf(ctx.BodyFields()))
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package inreq
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeBodyFields(t *testing.T) {
+	type Address struct {
+		Street string `json:"street"`
+		Number int    `json:"number"`
+	}
+
+	type Body struct {
+		Name    string     `json:"name"`
+		Email   *string    `json:"email"`
+		Address Address    `json:"address"`
+		Tags    []string   `json:"tags"`
+		Fields  BodyFields `json:"-"`
+	}
+
+	type DataType struct {
+		Body *Body `inreq:"body"`
+	}
+
+	r := httptest.NewRequest(http.MethodPatch, "/",
+		strings.NewReader(`{"name":"John","email":null,"address":{"number":12},"tags":["a"],"a/b":1}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	data, err := DecodeType[DataType](r)
+	require.NoError(t, err)
+	require.Equal(t, "John", data.Body.Name)
+	require.Equal(t, []string{"/address", "/address/number", "/a~1b", "/email", "/name", "/tags", "/tags/0"},
+		data.Body.Fields.Pointers())
+	require.True(t, data.Body.Fields.Has("/email"))
+	require.False(t, data.Body.Fields.Has("/address/street"))
+}
+
+func TestDecodeBodyFieldsContext(t *testing.T) {
+	type DataType struct {
+		Body struct {
+			Name string `json:"name"`
+		} `inreq:"body"`
+		Fields BodyFields `inreq:"bodyfields"`
+	}
+
+	r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"name":"John"}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	data, err := DecodeType[DataType](r, WithDecodeOperation("bodyfields", &testDecodeOperationBodyFields{}),
+		WithBodyFields(true))
+	require.NoError(t, err)
+	require.Equal(t, BodyFields{"/name": true}, data.Fields)
+
+	// the body is not recorded if not requested.
+	r = httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"name":"John"}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	data, err = DecodeType[DataType](r, WithDecodeOperation("bodyfields", &testDecodeOperationBodyFields{}))
+	require.NoError(t, err)
+	require.Nil(t, data.Fields)
+}
+
+type testDecodeOperationBodyFields struct {
+}
+
+func (d *testDecodeOperationBodyFields) Decode(ctx DecodeContext, r *http.Request, isList bool, field reflect.Value,
+	tag *Tag) (bool, any, error) {
+	field.Set(reflect.ValueOf(ctx.BodyFields()))
+	return true, IgnoreDecodeValue, nil
+}
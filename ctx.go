@@ -1,6 +1,12 @@
 package inreq
 
-import "github.com/rrgmc/instruct"
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+
+	"github.com/rrgmc/instruct"
+)
 
 // DecodeContext is the context sent to DecodeOperation.
 type DecodeContext interface {
@@ -21,6 +27,28 @@ type DecodeContext interface {
 	EnsureAllQueryUsed() bool
 	// EnsureAllFormUsed returns whether to check if all form parameters were used.
 	EnsureAllFormUsed() bool
+	// CollectErrors returns whether to continue decoding on field errors, returning all of them at the end.
+	CollectErrors() bool
+	// IsSensitive returns whether the field values must not be exposed in errors or debug output. The "sensitive"
+	// tag option has precedence over SensitiveOperation and the ValueRedactor.
+	IsSensitive(tag *Tag) bool
+	// MergeMode returns how decoded values are set on fields which already contain a value.
+	MergeMode() MergeMode
+	// BodyFields returns the JSON pointers of the fields present in the JSON body, or nil if a JSON body wasn't
+	// decoded yet. They are only recorded if the body struct has a BodyFields field or if WithBodyFields is set.
+	BodyFields() BodyFields
+	// FieldPath returns the path of the struct field being decoded, in the same format as [RequiredError.FieldName].
+	FieldPath(field reflect.Value) string
+}
+
+// DecodeContextValues is implemented by the DecodeContext of the decoders, to store values which are shared between
+// the fields of a single decode, like a parsed token used by multiple fields. Values are discarded when the decode
+// finishes.
+type DecodeContextValues interface {
+	// Value returns the value set for the key, or nil if not set.
+	Value(key any) any
+	// SetValue sets the value for the key.
+	SetValue(key any, value any)
 }
 
 type decodeContext struct {
@@ -32,6 +60,53 @@ type decodeContext struct {
 	sliceSplitSeparator string
 	ensureAllQueryUsed  bool
 	ensureAllFormUsed   bool
+	collectErrors       bool
+	operations          map[string]DecodeOperation
+	valueRedactor       ValueRedactor
+	errorMessages       ErrorMessages
+	sourceTracer        SourceTracer
+	mergeMode           MergeMode
+	recordBodyFields    bool
+	languages           []string     // languages to use for error messages, in order of preference.
+	errors              DecodeErrors // errors collected if collectErrors is true.
+	jsonBody            []byte       // decoded JSON body, used to build bodyFields.
+	bodyFields          BodyFields
+	data                reflect.Value   // root value being decoded.
+	mapTags             MapTags         // decode-specific MapTags.
+	optionalRequired    map[string]bool // paths of the Optional fields with the "required" tag option set.
+	values              map[any]any     // values set with SetValue.
+	fieldTarget         reflect.Value   // value decoded in place of fieldTargetOf when using a merge mode.
+	fieldTargetOf       reflect.Value
+}
+
+func newDecodeContext(r *http.Request, defaultOptions *instruct.DefaultOptions[*http.Request, DecodeContext],
+	sharedOptions *sharedDefaultOptions, optns *decodeOptions, data any) *decodeContext {
+	ret := &decodeContext{
+		DefaultDecodeContext: instruct.NewDefaultDecodeContext(defaultOptions.FieldNameMapper),
+		pathValue:            sharedOptions.pathValue,
+		bodyDecoder:          sharedOptions.bodyDecoder,
+		sliceSplitSeparator:  sharedOptions.sliceSplitSeparator,
+		allowReadBody:        optns.allowReadBody,
+		ensureAllQueryUsed:   optns.ensureAllQueryUsed,
+		ensureAllFormUsed:    optns.ensureAllFormUsed,
+		collectErrors:        optns.collectErrors,
+		operations:           defaultOptions.DecodeOperations,
+		valueRedactor:        sharedOptions.valueRedactor,
+		errorMessages:        sharedOptions.errorMessages,
+		sourceTracer:         optns.sourceTracer,
+		mergeMode:            optns.mergeMode,
+		recordBodyFields:     optns.bodyFields,
+		data:                 reflect.ValueOf(data),
+		mapTags:              optns.options.MapTags,
+	}
+	if ret.errorMessages != nil {
+		if optns.language != "" {
+			ret.languages = []string{optns.language}
+		} else {
+			ret.languages = parseAcceptLanguage(r.Header.Get("Accept-Language"))
+		}
+	}
+	return ret
 }
 
 func (d *decodeContext) PathValue() PathValue {
@@ -65,3 +140,101 @@ func (d *decodeContext) EnsureAllQueryUsed() bool {
 func (d *decodeContext) EnsureAllFormUsed() bool {
 	return d.ensureAllFormUsed
 }
+
+func (d *decodeContext) CollectErrors() bool {
+	return d.collectErrors
+}
+
+func (d *decodeContext) MergeMode() MergeMode {
+	return d.mergeMode
+}
+
+func (d *decodeContext) IsSensitive(tag *Tag) bool {
+	if value, ok := tag.Options.Get("sensitive"); ok {
+		sensitive, err := strconv.ParseBool(value)
+		return err != nil || sensitive // invalid values are considered sensitive to be safe.
+	}
+	if s, ok := d.operations[tag.Operation].(SensitiveOperation); ok && s.IsSensitive(tag) {
+		return true
+	}
+	return d.valueRedactor != nil && d.valueRedactor(tag.Operation, tag.Name)
+}
+
+func (d *decodeContext) Value(key any) any {
+	return d.values[key]
+}
+
+func (d *decodeContext) SetValue(key any, value any) {
+	if d.values == nil {
+		d.values = map[any]any{}
+	}
+	d.values[key] = value
+}
+
+func (d *decodeContext) addError(err FieldError) {
+	d.errors = append(d.errors, err)
+}
+
+// decodeErrors returns the collected errors, or nil if there were none.
+func (d *decodeContext) decodeErrors() error {
+	if len(d.errors) == 0 {
+		return nil
+	}
+	return d.errors
+}
+
+func (d *decodeContext) localizeError(err FieldError) (string, bool) {
+	if d.errorMessages == nil {
+		return "", false
+	}
+	return localizeError(d.errorMessages, d.languages, err)
+}
+
+func (d *decodeContext) traceSource(source FieldSource) {
+	if d.sourceTracer != nil {
+		d.sourceTracer(source)
+	}
+}
+
+func (d *decodeContext) recordJSONBody(data any) bool {
+	// the merge mode uses the fields present in the body to know which zero values were sent.
+	return d.recordBodyFields || d.mergeMode == MergeModeMerge || hasBodyFieldsField(data)
+}
+
+func (d *decodeContext) setJSONBody(data []byte) {
+	d.jsonBody = data
+	d.bodyFields = nil
+}
+
+func (d *decodeContext) BodyFields() BodyFields {
+	if d.bodyFields == nil && d.jsonBody != nil {
+		d.bodyFields = parseBodyFields(d.jsonBody)
+		d.jsonBody = nil
+	}
+	return d.bodyFields
+}
+
+func (d *decodeContext) setFieldTarget(target reflect.Value, field reflect.Value) {
+	d.fieldTarget, d.fieldTargetOf = target, field
+}
+
+func (d *decodeContext) FieldPath(field reflect.Value) string {
+	if d.fieldTarget.IsValid() && field.CanAddr() && field.Type() == d.fieldTarget.Type() &&
+		field.UnsafeAddr() == d.fieldTarget.UnsafeAddr() {
+		field = d.fieldTargetOf
+	}
+	if path, ok := fieldPath(d.data, field); ok {
+		return path
+	}
+	return field.Type().String()
+}
+
+// isOptionalRequired returns whether the "required" tag option is set on the Optional field, using the decoder
+// which parses the tags with DefaultRequired set to false.
+func (d *decodeContext) isOptionalRequired(dec *instruct.Decoder[*http.Request, DecodeContext],
+	field reflect.Value) bool {
+	if d.optionalRequired == nil {
+		d.optionalRequired = findOptionalRequired(dec, d.data.Type(), d.mapTags)
+	}
+	return d.optionalRequired[d.FieldPath(field)]
+}
@@ -13,7 +13,7 @@ type Decoder struct {
 	defaultOptions defaultOptions
 }
 
-// NewDecoder creates a Decoder instance with the default decode operations (query, path, header, form, body).
+// NewDecoder creates a Decoder instance with the default decode operations (query, path, header, form, body, cookie).
 func NewDecoder(options ...DefaultOption) *Decoder {
 	return NewCustomDecoder(inoptions.ConcatOptionsBefore[DefaultOption](options, WithDefaultDecodeOperations())...)
 }
@@ -23,6 +23,7 @@ func NewDecoder(options ...DefaultOption) *Decoder {
 func NewCustomDecoder(options ...DefaultOption) *Decoder {
 	optns := defaultDefaultOptions()
 	optns.apply(options...)
+	wrapDecodeOperations(&optns.options)
 
 	return &Decoder{
 		dec:            instruct.NewDecoder[*http.Request, DecodeContext](optns.options),
@@ -32,20 +33,16 @@ func NewCustomDecoder(options ...DefaultOption) *Decoder {
 
 // Decode decodes the http request to the struct passed in "data".
 func (d *Decoder) Decode(r *http.Request, data any, options ...DecodeOption) error {
-	optns := defaultDecodeOptions()
+	optns := d.defaultOptions.defaultDecodeOptions
 	optns.apply(options...)
 
-	optns.options.Ctx = &decodeContext{
-		DefaultDecodeContext: instruct.NewDefaultDecodeContext(d.defaultOptions.options.FieldNameMapper),
-		pathValue:            d.defaultOptions.pathValue,
-		bodyDecoder:          d.defaultOptions.bodyDecoder,
-		sliceSplitSeparator:  d.defaultOptions.sliceSplitSeparator,
-		allowReadBody:        optns.allowReadBody,
-		ensureAllQueryUsed:   optns.ensureAllQueryUsed,
-		ensureAllFormUsed:    optns.ensureAllFormUsed,
-	}
+	ctx := newDecodeContext(r, &d.defaultOptions.options, &d.defaultOptions.sharedDefaultOptions, &optns, data)
+	optns.options.Ctx = ctx
 
-	return d.dec.Decode(r, data, optns.options)
+	if err := d.dec.Decode(r, data, optns.options); err != nil {
+		return err
+	}
+	return ctx.decodeErrors()
 }
 
 // Decode decodes the http request to the struct passed in "data" using NewDecoder.
@@ -2,6 +2,7 @@ package inreq
 
 import (
 	"net/http"
+	"reflect"
 
 	"github.com/rrgmc/instruct"
 	inoptions "github.com/rrgmc/instruct/options"
@@ -9,11 +10,11 @@ import (
 
 // TypeDecoder decodes http requests to structs.
 type TypeDecoder[T any] struct {
-	dec            *instruct.TypeDecoder[*http.Request, DecodeContext, T]
+	dec            *instruct.Decoder[*http.Request, DecodeContext]
 	defaultOptions typeDefaultOptions
 }
 
-// NewTypeDecoder creates a Decoder instance with the default decode operations (query, path, header, form, body).
+// NewTypeDecoder creates a Decoder instance with the default decode operations (query, path, header, form, body, cookie).
 func NewTypeDecoder[T any](options ...TypeDefaultOption) *TypeDecoder[T] {
 	return NewCustomTypeDecoder[T](inoptions.ConcatOptionsBefore[TypeDefaultOption](options, WithDefaultDecodeOperations())...)
 }
@@ -23,29 +24,38 @@ func NewTypeDecoder[T any](options ...TypeDefaultOption) *TypeDecoder[T] {
 func NewCustomTypeDecoder[T any](options ...TypeDefaultOption) *TypeDecoder[T] {
 	optns := defaultTypeDefaultOptions()
 	optns.apply(options...)
+	wrapDecodeOperations(&optns.options.DefaultOptions)
+
+	// the instruct Decoder is used instead of the instruct TypeDecoder, as the decode context needs the decoded
+	// value to find the path of the fields. The struct info is only built for a single type, so it is always cached.
+	var data T
+	optns.options.StructInfoCache(true)
+	if optns.options.MapTags != nil {
+		optns.options.DefaultMapTagsSet(reflect.TypeOf(data), optns.options.MapTags)
+	}
 
 	return &TypeDecoder[T]{
-		dec:            instruct.NewTypeDecoder[*http.Request, DecodeContext, T](optns.options),
+		dec:            instruct.NewDecoder[*http.Request, DecodeContext](optns.options.DefaultOptions),
 		defaultOptions: optns,
 	}
 }
 
 // Decode decodes the http request to the struct passed in "data".
 func (d *TypeDecoder[T]) Decode(r *http.Request, options ...TypeDecodeOption) (T, error) {
-	optns := defaultDecodeOptions()
+	optns := d.defaultOptions.defaultDecodeOptions
 	optns.applyType(options...)
 
-	optns.options.Ctx = &decodeContext{
-		DefaultDecodeContext: instruct.NewDefaultDecodeContext(d.defaultOptions.options.FieldNameMapper),
-		pathValue:            d.defaultOptions.pathValue,
-		bodyDecoder:          d.defaultOptions.bodyDecoder,
-		sliceSplitSeparator:  d.defaultOptions.sliceSplitSeparator,
-		allowReadBody:        optns.allowReadBody,
-		ensureAllQueryUsed:   optns.ensureAllQueryUsed,
-		ensureAllFormUsed:    optns.ensureAllFormUsed,
-	}
+	// creates a new instance of the type.
+	data := decodeTypeNew[T]()
+
+	ctx := newDecodeContext(r, &d.defaultOptions.options.DefaultOptions, &d.defaultOptions.sharedDefaultOptions,
+		&optns, &data)
+	optns.options.Ctx = ctx
 
-	return d.dec.Decode(r, optns.options)
+	if err := d.dec.Decode(r, &data, optns.options); err != nil {
+		return data, err
+	}
+	return data, ctx.decodeErrors()
 }
 
 // DecodeType decodes the http request to the struct passed in "data" using NewDecoder.
@@ -64,3 +74,12 @@ func CustomDecodeType[T any](r *http.Request, options ...AnyTypeOption) (T, erro
 	return NewTypeDecoder[T](inoptions.ExtractOptions[TypeDefaultOption](options)...).Decode(r,
 		inoptions.ExtractOptions[TypeDecodeOption](options)...)
 }
+
+// decodeTypeNew creates a new value of the type, initializing a pointer if needed.
+func decodeTypeNew[T any]() T {
+	var v T
+	if typ := reflect.TypeOf(v); typ != nil && typ.Kind() == reflect.Pointer {
+		return reflect.New(typ.Elem()).Interface().(T)
+	}
+	return v
+}
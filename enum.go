@@ -0,0 +1,192 @@
+package inreq
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/rrgmc/instruct/types"
+)
+
+// Enum is implemented by string-based types which accept only a set of values.
+// Values from all operations, including the fields of decoded bodies, are checked against it, and an EnumError is
+// returned for other values.
+// The "casefold=true" tag option matches values case-insensitively, setting the field to the registered value.
+type Enum interface {
+	Values() []string
+}
+
+var (
+	enumType   = reflect.TypeOf(new(Enum)).Elem()
+	enumValues sync.Map // registered enum values, by type.
+)
+
+// RegisterEnum registers the accepted values of a string-based type, for types which can't implement Enum.
+func RegisterEnum[T ~string](values ...string) {
+	enumValues.Store(reflect.TypeOf(*new(T)), append([]string{}, values...))
+	// types which didn't contain enum values may contain the new one.
+	enumTypes.Range(func(key, value any) bool {
+		enumTypes.Delete(key)
+		return true
+	})
+}
+
+// EnumValues returns the accepted values of a type registered with RegisterEnum or implementing Enum, like
+// for schema generation.
+func EnumValues(typ reflect.Type) ([]string, bool) {
+	typ = reflectTypeElem(typ)
+	if values, ok := enumValues.Load(typ); ok {
+		return values.([]string), true
+	}
+	switch {
+	case typ.Implements(enumType):
+		return reflect.Zero(typ).Interface().(Enum).Values(), true
+	case reflect.PointerTo(typ).Implements(enumType):
+		return reflect.New(typ).Interface().(Enum).Values(), true
+	}
+	return nil, false
+}
+
+// EnumValuesOf returns the accepted values of the type T. See EnumValues.
+func EnumValuesOf[T any]() ([]string, bool) {
+	return EnumValues(reflect.TypeOf(new(T)).Elem())
+}
+
+// An EnumError is returned when a value is not one of the accepted values of an enum type.
+type EnumError struct {
+	Value   string
+	Allowed []string
+}
+
+func (e EnumError) Error() string {
+	return fmt.Sprintf("invalid value '%s', allowed values: %s", e.Value, strings.Join(e.Allowed, ", "))
+}
+
+// convertEnumValue checks the value of string-based enum types.
+func convertEnumValue(tag *Tag, typ reflect.Type, value string) (any, bool, error) {
+	if typ.Kind() != reflect.String {
+		return nil, false, nil
+	}
+	values, ok := EnumValues(typ)
+	if !ok {
+		return nil, false, nil
+	}
+	casefold, err := tag.Options.BoolValue("casefold", false)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: invalid 'casefold' option: %w", ErrInvalidConfiguration, err)
+	}
+	for _, v := range values {
+		if v == value || (casefold && strings.EqualFold(v, value)) {
+			return reflect.ValueOf(v).Convert(typ).Interface(), true, nil
+		}
+	}
+	return nil, false, EnumError{Value: value, Allowed: values}
+}
+
+// enumTypes caches whether each body type contains enum values.
+var enumTypes sync.Map // map[reflect.Type]bool
+
+// checkBodyEnums checks the enum values of a decoded body, as the body decoders set them directly. Empty values
+// are not checked, as they are also the value of fields not present in the body.
+func checkBodyEnums(v reflect.Value) error {
+	if !hasEnumValues(v.Type()) {
+		return nil
+	}
+	path, err := findInvalidEnum(v, "")
+	if err != nil {
+		if path != "" {
+			err = fmt.Errorf("%w: body field '%s': %w", ErrCoerceInvalid, path, err)
+		} else {
+			err = fmt.Errorf("%w: %w", ErrCoerceInvalid, err)
+		}
+		return types.NewCoerceError(err)
+	}
+	return nil
+}
+
+// hasEnumValues returns whether the type contains enum values, using a cache.
+func hasEnumValues(typ reflect.Type) bool {
+	if has, ok := enumTypes.Load(typ); ok {
+		return has.(bool)
+	}
+	has := findEnumValues(typ, map[reflect.Type]bool{})
+	enumTypes.Store(typ, has)
+	return has
+}
+
+func findEnumValues(typ reflect.Type, visited map[reflect.Type]bool) bool {
+	if visited[typ] {
+		return false
+	}
+	visited[typ] = true
+
+	switch typ.Kind() {
+	case reflect.String:
+		_, ok := EnumValues(typ)
+		return ok
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return findEnumValues(typ.Elem(), visited)
+	case reflect.Struct:
+		if reflect.PointerTo(typ).Implements(optionalFieldType) {
+			// the first field of Optional is the value.
+			return findEnumValues(typ.Field(0).Type, visited)
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			if sf := typ.Field(i); sf.IsExported() && findEnumValues(sf.Type, visited) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findInvalidEnum returns the path and error of the first invalid enum value.
+func findInvalidEnum(v reflect.Value, path string) (string, error) {
+	switch v.Kind() {
+	case reflect.String:
+		if values, ok := EnumValues(v.Type()); ok && v.Len() > 0 {
+			for _, value := range values {
+				if value == v.String() {
+					return "", nil
+				}
+			}
+			return path, EnumError{Value: v.String(), Allowed: values}
+		}
+	case reflect.Pointer:
+		if !v.IsNil() {
+			return findInvalidEnum(v.Elem(), path)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if p, err := findInvalidEnum(v.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return p, err
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if p, err := findInvalidEnum(iter.Value(), fmt.Sprintf("%s[%v]", path, iter.Key())); err != nil {
+				return p, err
+			}
+		}
+	case reflect.Struct:
+		if o, ok := asOptional(v); ok {
+			return findInvalidEnum(o.optionalValue(), path)
+		}
+		for i := 0; i < v.NumField(); i++ {
+			sf := v.Type().Field(i)
+			if !sf.IsExported() || !hasEnumValues(sf.Type) {
+				continue
+			}
+			fpath := sf.Name
+			if path != "" {
+				fpath = path + "." + sf.Name
+			}
+			if p, err := findInvalidEnum(v.Field(i), fpath); err != nil {
+				return p, err
+			}
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,202 @@
+package inreq
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testEnumStatus string
+
+type testEnumSort string
+
+func (testEnumSort) Values() []string {
+	return []string{"asc", "desc"}
+}
+
+func init() {
+	RegisterEnum[testEnumStatus]("open", "closed")
+}
+
+func TestDecodeEnum(t *testing.T) {
+	type DataType struct {
+		Status   testEnumStatus   `inreq:"query"`
+		Statuses []testEnumStatus `inreq:"query,name=st"`
+		Sort     *testEnumSort    `inreq:"header,name=X-Sort,casefold=true"`
+		Plain    string           `inreq:"query"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?status=open&st=open&st=closed&plain=any", nil)
+	r.Header.Set("X-Sort", "DESC")
+
+	data, err := DecodeType[DataType](r)
+	require.NoError(t, err)
+	require.Equal(t, testEnumStatus("open"), data.Status)
+	require.Equal(t, []testEnumStatus{"open", "closed"}, data.Statuses)
+	require.Equal(t, testEnumSort("desc"), *data.Sort)
+	require.Equal(t, "any", data.Plain)
+}
+
+func TestDecodeEnumError(t *testing.T) {
+	type DataType struct {
+		Status   testEnumStatus   `inreq:"query"`
+		Statuses []testEnumStatus `inreq:"query,name=st"`
+		Sort     testEnumSort     `inreq:"query"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?status=Open&st=open&st=pending&sort=DESC", nil)
+	r.Header.Set("Accept-Language", "pt")
+
+	err := Decode(r, &DataType{}, WithCollectErrors(true), WithErrorMessages(MessageCatalog{
+		"pt": {
+			MessageEnum: "o parâmetro '{{.TagName}}' deve ser um de {{range $i, $v := .Allowed}}{{if $i}}, {{end}}{{$v}}{{end}}",
+		},
+	}))
+	var derrs DecodeErrors
+	require.ErrorAs(t, err, &derrs)
+	require.Len(t, derrs, 3)
+
+	var enumErr EnumError
+	require.ErrorAs(t, derrs[0], &enumErr)
+	require.Equal(t, EnumError{Value: "Open", Allowed: []string{"open", "closed"}}, enumErr)
+	require.ErrorIs(t, derrs[0], ErrCoerceInvalid)
+	require.Equal(t, "o parâmetro 'status' deve ser um de open, closed", derrs[0].Message)
+
+	require.ErrorAs(t, derrs[1], &enumErr)
+	require.Equal(t, "pending", enumErr.Value)
+
+	require.ErrorAs(t, derrs[2], &enumErr)
+	require.Equal(t, []string{"asc", "desc"}, enumErr.Allowed)
+
+	err = Decode(r, &struct {
+		Sort testEnumSort `inreq:"query,casefold=maybe"`
+	}{}, WithCollectErrors(true))
+	require.ErrorIs(t, err, ErrInvalidConfiguration)
+}
+
+func TestDecodeEnumBody(t *testing.T) {
+	type Item struct {
+		Status testEnumStatus `json:"status" xml:"status"`
+	}
+
+	type Body struct {
+		Sort   testEnumSort             `json:"sort" xml:"sort"`
+		Items  []Item                   `json:"items" xml:"items"`
+		ByName map[string]*Item         `json:"by_name" xml:"-"`
+		Next   Optional[testEnumStatus] `json:"next" xml:"-"`
+	}
+
+	type DataType struct {
+		Body Body `inreq:"body"`
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantPath    string
+		wantValue   string
+	}{
+		{
+			name:        "valid",
+			contentType: "application/json",
+			body:        `{"sort":"asc","items":[{"status":"open"},{}],"by_name":{"a":{"status":"closed"}},"next":"open"}`,
+		},
+		{
+			name:        "field",
+			contentType: "application/json",
+			body:        `{"sort":"up"}`,
+			wantPath:    "Sort",
+			wantValue:   "up",
+		},
+		{
+			name:        "slice",
+			contentType: "application/json",
+			body:        `{"items":[{"status":"open"},{"status":"pending"}]}`,
+			wantPath:    "Items[1].Status",
+			wantValue:   "pending",
+		},
+		{
+			name:        "map",
+			contentType: "application/json",
+			body:        `{"by_name":{"a":{"status":"pending"}}}`,
+			wantPath:    "ByName[a].Status",
+			wantValue:   "pending",
+		},
+		{
+			name:        "optional",
+			contentType: "application/json",
+			body:        `{"next":"pending"}`,
+			wantPath:    "Next",
+			wantValue:   "pending",
+		},
+		{
+			name:        "xml",
+			contentType: "application/xml",
+			body:        `<Body><sort>up</sort></Body>`,
+			wantPath:    "Sort",
+			wantValue:   "up",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			r.Header.Set("Content-Type", tt.contentType)
+
+			_, err := DecodeType[DataType](r)
+			if tt.wantPath == "" {
+				require.NoError(t, err)
+				return
+			}
+			var enumErr EnumError
+			require.ErrorAs(t, err, &enumErr)
+			require.Equal(t, tt.wantValue, enumErr.Value)
+			require.ErrorIs(t, err, ErrCoerceInvalid)
+			require.ErrorContains(t, err, "body field '"+tt.wantPath+"'")
+		})
+	}
+}
+
+func TestDecodeEnumBodyRegisterLater(t *testing.T) {
+	type testEnumLevel string
+
+	type DataType struct {
+		Body struct {
+			Level testEnumLevel `json:"level"`
+		} `inreq:"body"`
+	}
+
+	newRequest := func() *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"level":"max"}`))
+		r.Header.Set("Content-Type", "application/json")
+		return r
+	}
+
+	_, err := DecodeType[DataType](newRequest())
+	require.NoError(t, err)
+
+	RegisterEnum[testEnumLevel]("low", "high")
+
+	_, err = DecodeType[DataType](newRequest())
+	var enumErr EnumError
+	require.ErrorAs(t, err, &enumErr)
+	require.Equal(t, "max", enumErr.Value)
+}
+
+func TestEnumValues(t *testing.T) {
+	values, ok := EnumValuesOf[testEnumStatus]()
+	require.True(t, ok)
+	require.Equal(t, []string{"open", "closed"}, values)
+
+	values, ok = EnumValues(reflect.TypeOf(new(testEnumSort)))
+	require.True(t, ok)
+	require.Equal(t, []string{"asc", "desc"}, values)
+
+	_, ok = EnumValuesOf[string]()
+	require.False(t, ok)
+}
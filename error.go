@@ -0,0 +1,156 @@
+package inreq
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rrgmc/instruct/types"
+)
+
+// ErrInvalidConfiguration is wrapped by errors caused by invalid struct tags or options, like an invalid validation
+// rule parameter, instead of by the request. These errors are never collected by WithCollectErrors.
+var ErrInvalidConfiguration = errors.New("invalid configuration")
+
+// A ValidationError is returned when a field value fails a validation rule set in the tag options.
+type ValidationError struct {
+	Operation string
+	FieldName string
+	TagName   string
+	Rule      string // validation rule, like "min" or "pattern".
+	Param     string // validation rule parameter, blank for boolean rules like "email".
+}
+
+func (e ValidationError) Error() string {
+	rule := e.Rule
+	if e.Param != "" {
+		rule += "=" + e.Param
+	}
+	return fmt.Sprintf("field '%s' (tag name '%s') with operation '%s' failed validation '%s'",
+		e.FieldName, e.TagName, e.Operation, rule)
+}
+
+// An UnsupportedMediaTypeError is returned when the request body media type is not supported or is invalid.
+type UnsupportedMediaTypeError struct {
+	MediaType string
+	Err       error // media type parsing error, if any.
+}
+
+func (e UnsupportedMediaTypeError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("error detecting body content type '%s': %s", e.MediaType, e.Err)
+	}
+	return fmt.Sprintf("unsupported body media type '%s'", e.MediaType)
+}
+
+func (e UnsupportedMediaTypeError) Unwrap() error {
+	return e.Err
+}
+
+// A BodyDecodeError is returned when the request body could not be parsed.
+type BodyDecodeError struct {
+	MediaType string
+	Err       error
+}
+
+func (e BodyDecodeError) Error() string {
+	return fmt.Sprintf("error parsing '%s' body: %s", e.MediaType, e.Err)
+}
+
+func (e BodyDecodeError) Unwrap() error {
+	return e.Err
+}
+
+// RedactedValue replaces the raw value in FieldError.Value for sensitive fields.
+const RedactedValue = "[REDACTED]"
+
+// A FieldError wraps any error returned while decoding a single field, with the Go field path, operation,
+// parameter name and the raw input value.
+type FieldError struct {
+	Operation string
+	FieldName string // complete Go field path, like "Filter.Owner.ID".
+	TagName   string // external parameter name, like the query parameter name.
+	Value     any    // raw value returned by the operation, usually a string or []string. Nil if not available.
+	Redacted  bool   // whether Value was replaced by RedactedValue because the field is sensitive.
+	Message   string // localized message from the ErrorMessages set with WithErrorMessages, if available.
+	Err       error
+}
+
+func (e FieldError) Error() string {
+	if e.Message != "" {
+		return e.Message
+	}
+	switch e.Err.(type) {
+	case RequiredError, ValidationError:
+		// these already contain the field information
+		return e.Err.Error()
+	}
+	if e.FieldName == "" {
+		return fmt.Sprintf("operation '%s': %s", e.Operation, e.Err)
+	}
+	return fmt.Sprintf("field '%s' (tag name '%s') with operation '%s': %s",
+		e.FieldName, e.TagName, e.Operation, e.Err)
+}
+
+func (e FieldError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeErrors is returned when WithCollectErrors is set, listing all errors found while decoding.
+// The errors.Is and errors.As functions can be used to check for any of its errors.
+type DecodeErrors []FieldError
+
+func (e DecodeErrors) Error() string {
+	if len(e) == 1 {
+		return e[0].Error()
+	}
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d decode errors: %s", len(e), strings.Join(msgs, "; "))
+}
+
+func (e DecodeErrors) Unwrap() []error {
+	ret := make([]error, 0, len(e))
+	for _, err := range e {
+		ret = append(ret, err)
+	}
+	return ret
+}
+
+// redactedError hides the message of an error which may contain a sensitive value, like coercion errors.
+// errors.Is and errors.As still work with the original error.
+type redactedError struct {
+	err error
+}
+
+func (e redactedError) Error() string {
+	return "invalid value (redacted)"
+}
+
+func (e redactedError) Is(target error) bool {
+	return errors.Is(e.err, target)
+}
+
+func (e redactedError) As(target any) bool {
+	return errors.As(e.err, target)
+}
+
+// redactError returns an error which doesn't contain any values in its message. Errors which are known to not
+// contain values are returned as-is, and CoerceError is kept to allow checking with errors.As.
+func redactError(err error) error {
+	var requiredErr RequiredError
+	var validationErr ValidationError
+	var notUsedErr ValuesNotUsedError
+	var unauthorizedErr UnauthorizedError
+	var coerceErr CoerceError
+	switch {
+	case errors.As(err, &requiredErr), errors.As(err, &validationErr), errors.As(err, &notUsedErr),
+		errors.As(err, &unauthorizedErr):
+		return err
+	case errors.As(err, &coerceErr):
+		return types.NewCoerceError(redactedError{err: err})
+	}
+	return redactedError{err: err}
+}
@@ -0,0 +1,163 @@
+package inreq
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Error message keys used to find message templates in ErrorMessages.
+const (
+	MessageRequired      = "required"      // RequiredError
+	MessageCoerce        = "coerce"        // CoerceError
+	MessageEnum          = "enum"          // EnumError. "coerce" is checked next.
+	MessageValidation    = "validation"    // ValidationError. "validation.<rule>" (like "validation.min") is checked first.
+	MessageBody          = "body"          // BodyDecodeError
+	MessageMediaType     = "mediatype"     // UnsupportedMediaTypeError
+	MessageNotUsed       = "notused"       // ValuesNotUsedError
+	MessageNotAcceptable = "notacceptable" // NotAcceptableError
+	MessageUnauthorized  = "unauthorized"  // UnauthorizedError
+	MessageDefault       = "default"       // any other error
+)
+
+// ErrorMessages returns message templates used to render localized error messages.
+// The templates use the [text/template] syntax, and receive an ErrorMessageData as data.
+type ErrorMessages interface {
+	// Message returns the message template for the language and message key.
+	Message(lang string, key string) (string, bool)
+}
+
+// ErrorMessageData is the data sent to the ErrorMessages templates.
+type ErrorMessageData struct {
+	Operation string
+	FieldName string
+	TagName   string
+	Value     any      // raw value, RedactedValue for sensitive fields.
+	Rule      string   // validation rule, for ValidationError.
+	Param     string   // validation rule parameter, for ValidationError.
+	MediaType string   // body media type, for BodyDecodeError and UnsupportedMediaTypeError.
+	Allowed   []string // allowed values, for EnumError and NotAcceptableError (the offers).
+	Err       error    // error cause.
+}
+
+// MessageCatalog is an ErrorMessages which maps languages to message keys to templates, like
+// {"pt": {"required": "o campo '{{.TagName}}' é obrigatório"}}.
+// Languages are matched case-insensitively, and "pt-BR" falls back to "pt" if not found.
+type MessageCatalog map[string]map[string]string
+
+func (c MessageCatalog) Message(lang string, key string) (string, bool) {
+	for _, l := range []string{lang, languageBase(lang)} {
+		for clang, messages := range c {
+			if strings.EqualFold(clang, l) {
+				if msg, ok := messages[key]; ok {
+					return msg, true
+				}
+			}
+		}
+	}
+	return "", false
+}
+
+var errorMessageTemplates sync.Map // cache of parsed templates.
+
+// localizeError renders the FieldError message using the first language which contains a message for it.
+// If the template is invalid, no message is returned, so the default one is used.
+func localizeError(messages ErrorMessages, languages []string, ferr FieldError) (string, bool) {
+	keys, data := errorMessageKeys(ferr)
+	for _, lang := range languages {
+		for _, key := range keys {
+			if msg, ok := messages.Message(lang, key); ok {
+				return renderErrorMessage(msg, data)
+			}
+		}
+	}
+	return "", false
+}
+
+// errorMessageKeys returns the message keys to check for the error, in order, and the template data.
+func errorMessageKeys(ferr FieldError) ([]string, ErrorMessageData) {
+	data := ErrorMessageData{
+		Operation: ferr.Operation,
+		FieldName: ferr.FieldName,
+		TagName:   ferr.TagName,
+		Value:     ferr.Value,
+		Err:       ferr.Err,
+	}
+
+	var requiredErr RequiredError
+	var validationErr ValidationError
+	var enumErr EnumError
+	var coerceErr CoerceError
+	var bodyErr BodyDecodeError
+	var mediaTypeErr UnsupportedMediaTypeError
+	var notUsedErr ValuesNotUsedError
+	var notAcceptableErr NotAcceptableError
+	var unauthorizedErr UnauthorizedError
+
+	var keys []string
+	switch {
+	case errors.As(ferr.Err, &unauthorizedErr):
+		keys = append(keys, MessageUnauthorized)
+	case errors.As(ferr.Err, &requiredErr):
+		keys = append(keys, MessageRequired)
+	case errors.As(ferr.Err, &validationErr):
+		data.Rule = validationErr.Rule
+		data.Param = validationErr.Param
+		keys = append(keys, MessageValidation+"."+validationErr.Rule, MessageValidation)
+	case errors.As(ferr.Err, &enumErr):
+		data.Allowed = enumErr.Allowed
+		keys = append(keys, MessageEnum, MessageCoerce)
+	case errors.As(ferr.Err, &coerceErr):
+		keys = append(keys, MessageCoerce)
+	case errors.As(ferr.Err, &mediaTypeErr):
+		data.MediaType = mediaTypeErr.MediaType
+		keys = append(keys, MessageMediaType)
+	case errors.As(ferr.Err, &bodyErr):
+		data.MediaType = bodyErr.MediaType
+		keys = append(keys, MessageBody)
+	case errors.As(ferr.Err, &notUsedErr):
+		keys = append(keys, MessageNotUsed)
+	case errors.As(ferr.Err, &notAcceptableErr):
+		data.Allowed = notAcceptableErr.Offers
+		keys = append(keys, MessageNotAcceptable)
+	}
+	return append(keys, MessageDefault), data
+}
+
+func renderErrorMessage(msg string, data ErrorMessageData) (string, bool) {
+	var tmpl *template.Template
+	if t, ok := errorMessageTemplates.Load(msg); ok {
+		tmpl = t.(*template.Template)
+	} else {
+		var err error
+		tmpl, err = template.New("message").Parse(msg)
+		if err != nil {
+			return "", false
+		}
+		errorMessageTemplates.Store(msg, tmpl)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", false
+	}
+	return b.String(), true
+}
+
+// parseAcceptLanguage returns the languages from an "Accept-Language" header, ordered by preference.
+func parseAcceptLanguage(header string) []string {
+	var ret []string
+	for _, qv := range ParseQualityValues(header) {
+		if qv.Value != "*" && qv.Q > 0 {
+			ret = append(ret, qv.Value)
+		}
+	}
+	return ret
+}
+
+// languageBase returns the base language of a language tag, like "pt" for "pt-BR".
+func languageBase(lang string) string {
+	base, _, _ := strings.Cut(lang, "-")
+	return base
+}
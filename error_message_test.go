@@ -0,0 +1,96 @@
+package inreq
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testMessageCatalog = MessageCatalog{
+	"pt": {
+		MessageRequired:            "o parâmetro '{{.TagName}}' é obrigatório",
+		MessageValidation:          "o parâmetro '{{.TagName}}' é inválido",
+		MessageValidation + ".max": "o parâmetro '{{.TagName}}' deve ser no máximo {{.Param}}",
+		MessageCoerce:              "o valor '{{.Value}}' do parâmetro '{{.TagName}}' é inválido",
+		MessageDefault:             "erro no parâmetro '{{.TagName}}'",
+	},
+	"es": {
+		MessageRequired: "el parámetro '{{.TagName}}' es obligatorio",
+	},
+}
+
+func TestDecodeErrorMessages(t *testing.T) {
+	type DataType struct {
+		Token  string `inreq:"header,name=X-Token"`
+		Count  int    `inreq:"query,max=5"`
+		Name   string `inreq:"query,min=3"`
+		Page   int    `inreq:"query"`
+		Secret int    `inreq:"query,sensitive=true"`
+	}
+
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		options        []DecodeOption
+		want           []string
+	}{
+		{
+			name:           "accept language",
+			acceptLanguage: "fr;q=0.9, pt-BR, en;q=0.5",
+			want: []string{
+				"o parâmetro 'X-Token' é obrigatório",
+				"o parâmetro 'count' deve ser no máximo 5",
+				"o parâmetro 'name' é inválido",
+				"o valor 'x' do parâmetro 'page' é inválido",
+				"o valor '[REDACTED]' do parâmetro 'secret' é inválido",
+			},
+		},
+		{
+			name:           "language option",
+			acceptLanguage: "pt",
+			options:        []DecodeOption{WithLanguage("es")},
+			want: []string{
+				"el parámetro 'X-Token' es obligatorio",
+				"", "", "", "",
+			},
+		},
+		{
+			name:           "unknown language",
+			acceptLanguage: "fr",
+			want:           []string{"", "", "", "", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/?count=10&name=ab&page=x&secret=y", nil)
+			r.Header.Set("Accept-Language", tt.acceptLanguage)
+
+			options := append([]DecodeOption{WithCollectErrors(true)}, tt.options...)
+			err := NewDecoder(WithErrorMessages(testMessageCatalog)).Decode(r, &DataType{}, options...)
+
+			var derrs DecodeErrors
+			require.ErrorAs(t, err, &derrs)
+			require.Len(t, derrs, len(tt.want))
+			for i, want := range tt.want {
+				require.Equal(t, want, derrs[i].Message)
+				if want != "" {
+					require.Equal(t, want, derrs[i].Error())
+				}
+			}
+
+			var rerr RequiredError
+			require.ErrorAs(t, err, &rerr)
+			var verr ValidationError
+			require.ErrorAs(t, err, &verr)
+			require.ErrorIs(t, err, ErrCoerceInvalid)
+		})
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	require.Equal(t, []string{"pt-BR", "en", "es"}, parseAcceptLanguage("es;q=0.5, *;q=0.1, pt-BR, fr;q=0, en;q=0.8"))
+	require.Empty(t, parseAcceptLanguage(""))
+}
@@ -0,0 +1,220 @@
+package inreq
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCollectErrors(t *testing.T) {
+	type Filter struct {
+		Owner int    `inreq:"query"`
+		Name  string `inreq:"query,min=3"`
+	}
+
+	type DataType struct {
+		Filter Filter `inreq:"recurse"`
+		Token  string `inreq:"header,name=X-Token"`
+		Page   int    `inreq:"query"`
+		Valid  string `inreq:"query"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?owner=abc&name=ab&page=x&valid=ok&extra=1", nil)
+
+	data := &DataType{}
+	err := Decode(r, data, WithCollectErrors(true), WithEnsureAllQueryUsed(true))
+
+	var derrs DecodeErrors
+	require.ErrorAs(t, err, &derrs)
+	require.Len(t, derrs, 5)
+
+	require.Equal(t, "Filter.Owner", derrs[0].FieldName)
+	require.Equal(t, OperationQuery, derrs[0].Operation)
+	require.Equal(t, "owner", derrs[0].TagName)
+	require.ErrorIs(t, derrs[0], ErrCoerceInvalid)
+	var cerr CoerceError
+	require.ErrorAs(t, derrs[0], &cerr)
+
+	require.Equal(t, "Filter.Name", derrs[1].FieldName)
+	var verr ValidationError
+	require.ErrorAs(t, derrs[1], &verr)
+
+	require.Equal(t, "Token", derrs[2].FieldName)
+	require.Equal(t, "X-Token", derrs[2].TagName)
+	var rerr RequiredError
+	require.ErrorAs(t, derrs[2], &rerr)
+	require.Equal(t, "Token", rerr.FieldName)
+
+	require.Equal(t, "Page", derrs[3].FieldName)
+
+	require.Equal(t, OperationQuery, derrs[4].Operation)
+	var nerr ValuesNotUsedError
+	require.ErrorAs(t, derrs[4], &nerr)
+
+	// errors.As works directly on the returned error.
+	require.ErrorAs(t, err, &rerr)
+	require.ErrorAs(t, err, &verr)
+	require.True(t, errors.Is(err, ErrCoerceInvalid))
+
+	require.Equal(t, "ok", data.Valid)
+}
+
+func TestDecodeCollectErrorsNone(t *testing.T) {
+	type DataType struct {
+		Page int `inreq:"query"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?page=2", nil)
+
+	v, err := DecodeType[DataType](r, WithCollectErrors(true))
+	require.NoError(t, err)
+	require.Equal(t, 2, v.Page)
+}
+
+func TestDecodeCollectErrorsDecoderDefault(t *testing.T) {
+	type DataType struct {
+		A int `inreq:"query"`
+		B int `inreq:"query"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?a=x", nil)
+
+	err := NewDecoder(WithCollectErrors(true)).Decode(r, &DataType{})
+	var derrs DecodeErrors
+	require.ErrorAs(t, err, &derrs)
+	require.Len(t, derrs, 2)
+
+	// decode option overrides the default one.
+	err = NewDecoder(WithCollectErrors(true)).Decode(r, &DataType{}, WithCollectErrors(false))
+	require.Error(t, err)
+	require.False(t, errors.As(err, &derrs))
+
+	_, err = NewTypeDecoder[DataType](WithCollectErrors(true)).Decode(r)
+	require.ErrorAs(t, err, &derrs)
+	require.Len(t, derrs, 2)
+}
+
+func TestDecodeFirstError(t *testing.T) {
+	type DataType struct {
+		A int `inreq:"query"`
+		B int `inreq:"query"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?a=x", nil)
+
+	err := Decode(r, &DataType{})
+	var cerr CoerceError
+	require.ErrorAs(t, err, &cerr)
+	var derrs DecodeErrors
+	require.False(t, errors.As(err, &derrs))
+}
+
+func TestDecodeFieldError(t *testing.T) {
+	type Owner struct {
+		ID int `inreq:"query,name=owner_id"`
+	}
+
+	type Filter struct {
+		Owner *Owner `inreq:"recurse"`
+	}
+
+	type DataType struct {
+		Filter Filter `inreq:"recurse"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?owner_id=abc", nil)
+
+	_, err := DecodeType[DataType](r)
+	var ferr FieldError
+	require.ErrorAs(t, err, &ferr)
+	require.Equal(t, "Filter.Owner.ID", ferr.FieldName)
+	require.Equal(t, OperationQuery, ferr.Operation)
+	require.Equal(t, "owner_id", ferr.TagName)
+	require.Equal(t, "abc", ferr.Value)
+	require.False(t, ferr.Redacted)
+	require.ErrorIs(t, err, ErrCoerceInvalid)
+}
+
+func TestDecodeFieldErrorRequired(t *testing.T) {
+	type DataType struct {
+		Val string `inreq:"header"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	err := Decode(r, &DataType{})
+	var ferr FieldError
+	require.ErrorAs(t, err, &ferr)
+	require.Equal(t, "Val", ferr.FieldName)
+	require.Nil(t, ferr.Value)
+	var rerr RequiredError
+	require.ErrorAs(t, err, &rerr)
+	require.Equal(t, "Val", rerr.FieldName)
+}
+
+func TestDecodeFieldErrorRedacted(t *testing.T) {
+	type DataType struct {
+		Secret int `inreq:"header,name=X-Secret"`
+		Public int `inreq:"header,name=X-Public"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Secret", "secret-value")
+	r.Header.Set("X-Public", "public-value")
+
+	err := Decode(r, &DataType{}, WithCollectErrors(true),
+		WithValueRedactor(func(operation string, name string) bool {
+			return operation == OperationHeader && name == "X-Secret"
+		}))
+	var derrs DecodeErrors
+	require.ErrorAs(t, err, &derrs)
+	require.Len(t, derrs, 2)
+	require.Equal(t, RedactedValue, derrs[0].Value)
+	require.True(t, derrs[0].Redacted)
+	require.Equal(t, "public-value", derrs[1].Value)
+	require.False(t, derrs[1].Redacted)
+}
+
+func TestDecodeFieldErrorSensitive(t *testing.T) {
+	type DataType struct {
+		Password int    `inreq:"form,sensitive=true"`
+		Auth     int    `inreq:"header,name=authorization"`
+		Cookie   int    `inreq:"header,sensitive=false"`
+		Public   string `inreq:"query,max=2"`
+		Session  int    `inreq:"cookie"`
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/?public=public-value", nil)
+	r.Form = map[string][]string{"password": {"password-value"}}
+	r.Header.Set("Authorization", "auth-value")
+	r.Header.Set("Cookie", "cookie-value")
+	r.AddCookie(&http.Cookie{Name: "session", Value: "session-value"})
+
+	err := Decode(r, &DataType{}, WithCollectErrors(true))
+	var derrs DecodeErrors
+	require.ErrorAs(t, err, &derrs)
+	require.Len(t, derrs, 5)
+
+	msg := err.Error()
+	require.NotContains(t, msg, "password-value")
+	require.NotContains(t, msg, "auth-value")
+	require.Contains(t, msg, "cookie-value")
+	require.NotContains(t, derrs[4].Error(), "session-value")
+
+	for i, redacted := range []bool{true, true, false, false, true} {
+		require.Equal(t, redacted, derrs[i].Redacted)
+		if redacted {
+			require.Equal(t, RedactedValue, derrs[i].Value)
+			require.ErrorIs(t, derrs[i], ErrCoerceInvalid)
+			var cerr CoerceError
+			require.ErrorAs(t, derrs[i], &cerr)
+			require.NotContains(t, cerr.Error(), "-value")
+		}
+	}
+
+	var verr ValidationError
+	require.ErrorAs(t, derrs[3], &verr)
+}
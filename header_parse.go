@@ -0,0 +1,130 @@
+package inreq
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+
+	"github.com/rrgmc/instruct/types"
+)
+
+// Values of the "value" tag option of the header operation.
+const (
+	HeaderValueMain   = "main"   // the main value, like the media type of "Content-Type".
+	HeaderValueParams = "params" // a map[string]string of all parameters.
+)
+
+// parseHeaderList parses comma-separated header field values following the RFC 9110 list rules (section 5.6.1),
+// across all header lines. Commas inside quoted strings don't split items, whitespace around items is removed,
+// and empty items are ignored. Items which consist of a single quoted string are unquoted.
+func parseHeaderList(values []string) []string {
+	var ret []string
+	for _, value := range values {
+		for _, item := range splitHeaderQuoted(value, ',') {
+			ret = appendHeaderListItem(ret, item)
+		}
+	}
+	return ret
+}
+
+// splitHeaderQuoted splits the value by the separator, except inside quoted strings.
+func splitHeaderQuoted(value string, sep byte) []string {
+	var ret []string
+	start, quoted := 0, false
+	for i := 0; i < len(value); i++ {
+		switch value[i] {
+		case '\\':
+			if quoted {
+				i++
+			}
+		case '"':
+			quoted = !quoted
+		case sep:
+			if !quoted {
+				ret = append(ret, value[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(ret, value[start:])
+}
+
+func appendHeaderListItem(list []string, item string) []string {
+	item = strings.Trim(item, " \t")
+	if item == "" {
+		return list
+	}
+	if s, ok := unquoteHeaderString(item); ok {
+		item = s
+	}
+	return append(list, item)
+}
+
+// unquoteHeaderString unquotes an RFC 9110 quoted-string (section 5.6.4), returning false if the value is not a
+// single quoted string.
+func unquoteHeaderString(value string) (string, bool) {
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return "", false
+	}
+	var b strings.Builder
+	for i := 1; i < len(value)-1; i++ {
+		switch value[i] {
+		case '\\':
+			i++
+			if i == len(value)-1 {
+				// the closing quote was escaped.
+				return "", false
+			}
+		case '"':
+			return "", false
+		}
+		b.WriteByte(value[i])
+	}
+	return b.String(), true
+}
+
+// decodeHeaderParams parses each header value like [mime.ParseMediaType], returning the main value, all
+// parameters or a single parameter, which takes precedence. Parameter names are case-insensitive.
+func decodeHeaderParams(values []string, isList bool, valueType string, param string) (bool, any, error) {
+	if param == "" && valueType != HeaderValueMain && valueType != HeaderValueParams {
+		return false, nil, fmt.Errorf("invalid header value option '%s'", valueType)
+	}
+
+	var strs []string
+	var params []map[string]string
+	for _, value := range values {
+		main, p, err := mime.ParseMediaType(value)
+		if err != nil {
+			return false, nil, types.NewCoerceError(fmt.Errorf("%w: %w", ErrCoerceInvalid, err))
+		}
+		switch {
+		case param != "":
+			if v, ok := p[strings.ToLower(param)]; ok {
+				strs = append(strs, v)
+			}
+		case valueType == HeaderValueMain:
+			strs = append(strs, main)
+		default:
+			params = append(params, p)
+		}
+	}
+
+	if params != nil {
+		if isList {
+			return true, params, nil
+		}
+		return true, params[0], nil
+	}
+	if len(strs) == 0 {
+		return false, nil, nil
+	}
+	if isList {
+		return true, strs, nil
+	}
+	return true, strs[0], nil
+}
+
+// quoteHeaderString returns the value as an RFC 9110 quoted-string.
+func quoteHeaderString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
@@ -0,0 +1,52 @@
+package inreq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHeaderList(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []string
+		want   []string
+	}{
+		{
+			name:   "single line",
+			values: []string{"gzip, br"},
+			want:   []string{"gzip", "br"},
+		},
+		{
+			name:   "multiple lines",
+			values: []string{"gzip;q=1.0,\tbr", "deflate"},
+			want:   []string{"gzip;q=1.0", "br", "deflate"},
+		},
+		{
+			name:   "empty items",
+			values: []string{", a ,, b,", " "},
+			want:   []string{"a", "b"},
+		},
+		{
+			name:   "quoted strings",
+			values: []string{`"Smith, John", "a \"b\" \\ c"`},
+			want:   []string{"Smith, John", `a "b" \ c`},
+		},
+		{
+			name:   "partially quoted",
+			values: []string{`W/"a,b", text;charset="x,y"`},
+			want:   []string{`W/"a,b"`, `text;charset="x,y"`},
+		},
+		{
+			name:   "escaped closing quote",
+			values: []string{`"a\"`},
+			want:   []string{`"a\"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, parseHeaderList(tt.values))
+		})
+	}
+}
@@ -0,0 +1,332 @@
+package inreq
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ByteRange is a range of the "Range" header (RFC 9110, section 14.1.2).
+type ByteRange struct {
+	First  int64 // first byte position, -1 for suffix ranges.
+	Last   int64 // last byte position (inclusive), -1 for open-ended and suffix ranges.
+	Suffix int64 // length of suffix ranges, like 500 for "-500".
+}
+
+// Bounds returns the offset and length of the range for a representation of the given size, or false if the range
+// is not satisfiable.
+func (r ByteRange) Bounds(size int64) (offset int64, length int64, ok bool) {
+	if r.First < 0 {
+		if r.Suffix == 0 || size == 0 {
+			return 0, 0, false
+		}
+		if r.Suffix > size {
+			return 0, size, true
+		}
+		return size - r.Suffix, r.Suffix, true
+	}
+	if r.First >= size {
+		return 0, 0, false
+	}
+	last := size - 1
+	if r.Last >= 0 && r.Last < last {
+		last = r.Last
+	}
+	return r.First, last - r.First + 1, true
+}
+
+func (r ByteRange) String() string {
+	switch {
+	case r.First < 0:
+		return fmt.Sprintf("-%d", r.Suffix)
+	case r.Last < 0:
+		return fmt.Sprintf("%d-", r.First)
+	}
+	return fmt.Sprintf("%d-%d", r.First, r.Last)
+}
+
+// ByteRanges are the ranges of a "Range" header using the "bytes" unit, like "bytes=0-499, -500".
+// Header fields of this type are parsed and validated.
+type ByteRanges []ByteRange
+
+// ParseByteRanges parses a "Range" header value. Only the "bytes" unit is supported.
+func ParseByteRanges(value string) (ByteRanges, error) {
+	unit, spec, ok := strings.Cut(strings.TrimSpace(value), "=")
+	if !ok {
+		return nil, errors.New("invalid range")
+	}
+	if !strings.EqualFold(unit, "bytes") {
+		return nil, fmt.Errorf("unsupported range unit '%s'", unit)
+	}
+
+	var ret ByteRanges
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.Trim(item, " \t")
+		if item == "" {
+			continue
+		}
+		first, last, ok := strings.Cut(item, "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid range '%s'", item)
+		}
+		if first == "" {
+			suffix, err := parseRangePosition(last)
+			if err != nil {
+				return nil, fmt.Errorf("invalid range '%s': %w", item, err)
+			}
+			ret = append(ret, ByteRange{First: -1, Last: -1, Suffix: suffix})
+			continue
+		}
+		r := ByteRange{Last: -1}
+		var err error
+		if r.First, err = parseRangePosition(first); err != nil {
+			return nil, fmt.Errorf("invalid range '%s': %w", item, err)
+		}
+		if last != "" {
+			if r.Last, err = parseRangePosition(last); err != nil {
+				return nil, fmt.Errorf("invalid range '%s': %w", item, err)
+			}
+			if r.Last < r.First {
+				return nil, fmt.Errorf("invalid range '%s': last position before first", item)
+			}
+		}
+		ret = append(ret, r)
+	}
+	if len(ret) == 0 {
+		return nil, errors.New("empty range")
+	}
+	return ret, nil
+}
+
+// parseRangePosition parses a non-negative position, which must contain only digits.
+func parseRangePosition(value string) (int64, error) {
+	for i := 0; i < len(value); i++ {
+		if !isDigit(value[i]) {
+			return 0, fmt.Errorf("invalid position '%s'", value)
+		}
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// ETag is an entity tag (RFC 9110, section 8.8.3).
+type ETag struct {
+	Tag  string // opaque tag, without quotes.
+	Weak bool
+}
+
+// ParseETag parses an entity tag, like `"xyz"` or `W/"xyz"`.
+func ParseETag(value string) (ETag, error) {
+	etag, rest, err := parseETag(strings.Trim(value, " \t"))
+	if err != nil {
+		return ETag{}, err
+	}
+	if rest != "" {
+		return ETag{}, fmt.Errorf("invalid entity tag '%s'", value)
+	}
+	return etag, nil
+}
+
+// StrongEqual returns whether both tags are strong and equal, which is used by "If-Match".
+func (e ETag) StrongEqual(other ETag) bool {
+	return !e.Weak && !other.Weak && e.Tag == other.Tag
+}
+
+// WeakEqual returns whether both tags are equal, ignoring the weak flag, which is used by "If-None-Match".
+func (e ETag) WeakEqual(other ETag) bool {
+	return e.Tag == other.Tag
+}
+
+func (e ETag) String() string {
+	if e.Weak {
+		return `W/"` + e.Tag + `"`
+	}
+	return `"` + e.Tag + `"`
+}
+
+// ETagList is a list of entity tags of the "If-Match" and "If-None-Match" headers, where Any is set for "*".
+// Header fields of this type are parsed and validated.
+type ETagList struct {
+	Any  bool
+	Tags []ETag
+}
+
+// ParseETagList parses an "If-Match" or "If-None-Match" header value.
+func ParseETagList(value string) (ETagList, error) {
+	value = strings.Trim(value, " \t")
+	if value == "*" {
+		return ETagList{Any: true}, nil
+	}
+
+	var ret ETagList
+	for value != "" {
+		value = strings.TrimLeft(value, " \t,")
+		if value == "" {
+			break
+		}
+		etag, rest, err := parseETag(value)
+		if err != nil {
+			return ETagList{}, err
+		}
+		ret.Tags = append(ret.Tags, etag)
+		value = strings.TrimLeft(rest, " \t")
+		if value != "" && value[0] != ',' {
+			return ETagList{}, fmt.Errorf("invalid entity tag list at '%s'", value)
+		}
+	}
+	if len(ret.Tags) == 0 {
+		return ETagList{}, errors.New("empty entity tag list")
+	}
+	return ret, nil
+}
+
+// MatchStrong returns whether the list matches the tag using the strong comparison, as used by "If-Match".
+// A "*" list matches any tag.
+func (l ETagList) MatchStrong(etag ETag) bool {
+	return l.match(etag, ETag.StrongEqual)
+}
+
+// MatchWeak returns whether the list matches the tag using the weak comparison, as used by "If-None-Match".
+// A "*" list matches any tag.
+func (l ETagList) MatchWeak(etag ETag) bool {
+	return l.match(etag, ETag.WeakEqual)
+}
+
+func (l ETagList) match(etag ETag, equal func(ETag, ETag) bool) bool {
+	if l.Any {
+		return true
+	}
+	for _, tag := range l.Tags {
+		if equal(tag, etag) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseETag parses an entity tag at the start of the value, returning the rest of the value. As commas are valid
+// inside entity tags, lists can't be split before parsing.
+func parseETag(value string) (ETag, string, error) {
+	var ret ETag
+	rest := value
+	if strings.HasPrefix(rest, "W/") {
+		ret.Weak = true
+		rest = rest[2:]
+	}
+	if rest == "" || rest[0] != '"' {
+		return ETag{}, "", fmt.Errorf("invalid entity tag '%s'", value)
+	}
+	for i := 1; i < len(rest); i++ {
+		switch c := rest[i]; {
+		case c == '"':
+			ret.Tag = rest[1:i]
+			return ret, rest[i+1:], nil
+		case c < 0x21 || c == 0x7f:
+			return ETag{}, "", fmt.Errorf("invalid entity tag '%s'", value)
+		}
+	}
+	return ETag{}, "", fmt.Errorf("unterminated entity tag '%s'", value)
+}
+
+// IfRange is the value of the "If-Range" header, which is either an entity tag or an HTTP date.
+// Header fields of this type are parsed and validated.
+type IfRange struct {
+	ETag *ETag     // entity tag, if the value is not a date.
+	Date time.Time // date, if the value is not an entity tag.
+}
+
+// ParseIfRange parses an "If-Range" header value.
+func ParseIfRange(value string) (IfRange, error) {
+	value = strings.Trim(value, " \t")
+	if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "W/") {
+		etag, err := ParseETag(value)
+		if err != nil {
+			return IfRange{}, err
+		}
+		return IfRange{ETag: &etag}, nil
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return IfRange{}, fmt.Errorf("invalid If-Range value '%s'", value)
+	}
+	return IfRange{Date: date}, nil
+}
+
+// Match returns whether the validator matches the current entity tag or last modification time of the
+// representation (RFC 9110, section 13.1.5), in which case the range request is evaluated. Entity tags use the
+// strong comparison, and dates must be equal to the modification time, which is truncated to seconds.
+func (r IfRange) Match(etag ETag, modified time.Time) bool {
+	if r.ETag != nil {
+		return r.ETag.StrongEqual(etag)
+	}
+	return !modified.IsZero() && r.Date.Equal(modified.Truncate(time.Second))
+}
+
+func (r IfRange) String() string {
+	if r.ETag != nil {
+		return r.ETag.String()
+	}
+	return r.Date.UTC().Format(http.TimeFormat)
+}
+
+var (
+	byteRangesType = reflect.TypeOf(ByteRanges{})
+	etagType       = reflect.TypeOf(ETag{})
+	etagListType   = reflect.TypeOf(ETagList{})
+	ifRangeType    = reflect.TypeOf(IfRange{})
+)
+
+// isCombinedHeaderType returns whether the header type parses all header lines as a single value.
+func isCombinedHeaderType(typ reflect.Type) bool {
+	return typ == byteRangesType || typ == etagListType
+}
+
+// convertHeaderTypeValue parses the ByteRanges, ETag, ETagList and IfRange types.
+func convertHeaderTypeValue(tag *Tag, typ reflect.Type, value string) (any, bool, error) {
+	var ret any
+	var err error
+	switch typ {
+	case byteRangesType:
+		ret, err = ParseByteRanges(value)
+	case etagType:
+		ret, err = ParseETag(value)
+	case etagListType:
+		ret, err = ParseETagList(value)
+	case ifRangeType:
+		ret, err = ParseIfRange(value)
+	default:
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return ret, true, nil
+}
+
+// httpDateHeaders are the headers whose time.Time fields use the HTTP date layout by default. Their values are not
+// split into lists, as dates contain commas. "If-Range" can also be an entity tag, use IfRange to accept both.
+var httpDateHeaders = map[string]bool{
+	"Date":                true,
+	"Expires":             true,
+	"If-Modified-Since":   true,
+	"If-Range":            true,
+	"If-Unmodified-Since": true,
+	"Last-Modified":       true,
+}
+
+// defaultTimeLayout returns the default "layout" tag option, which is LayoutHTTPDate for date headers like
+// "If-Modified-Since".
+func defaultTimeLayout(tag *Tag) string {
+	if tag.Operation == OperationHeader && httpDateHeaders[http.CanonicalHeaderKey(tag.Name)] {
+		return LayoutHTTPDate
+	}
+	return LayoutRFC3339
+}
+
+// isHTTPDateValue returns whether the header values are HTTP dates, which are not split into lists by default.
+func isHTTPDateValue(tag *Tag) bool {
+	return tag.Options.Value("layout", defaultTimeLayout(tag)) == LayoutHTTPDate
+}
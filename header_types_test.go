@@ -0,0 +1,205 @@
+package inreq
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseByteRanges(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    ByteRanges
+		wantErr bool
+	}{
+		{value: "bytes=0-499", want: ByteRanges{{First: 0, Last: 499}}},
+		{value: "Bytes=500- , -200,", want: ByteRanges{{First: 500, Last: -1}, {First: -1, Last: -1, Suffix: 200}}},
+		{value: "items=0-1", wantErr: true},
+		{value: "bytes=5-1", wantErr: true},
+		{value: "bytes=-", wantErr: true},
+		{value: "bytes=+1-2", wantErr: true},
+		{value: "bytes=", wantErr: true},
+		{value: "0-1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseByteRanges(tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestByteRangeBounds(t *testing.T) {
+	tests := []struct {
+		r      ByteRange
+		size   int64
+		offset int64
+		length int64
+		ok     bool
+	}{
+		{r: ByteRange{First: 0, Last: 499}, size: 1000, offset: 0, length: 500, ok: true},
+		{r: ByteRange{First: 500, Last: 2000}, size: 1000, offset: 500, length: 500, ok: true},
+		{r: ByteRange{First: 900, Last: -1}, size: 1000, offset: 900, length: 100, ok: true},
+		{r: ByteRange{First: -1, Last: -1, Suffix: 200}, size: 1000, offset: 800, length: 200, ok: true},
+		{r: ByteRange{First: -1, Last: -1, Suffix: 2000}, size: 1000, offset: 0, length: 1000, ok: true},
+		{r: ByteRange{First: 1000, Last: -1}, size: 1000},
+		{r: ByteRange{First: -1, Last: -1, Suffix: 0}, size: 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.r.String(), func(t *testing.T) {
+			offset, length, ok := tt.r.Bounds(tt.size)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.offset, offset)
+			require.Equal(t, tt.length, length)
+		})
+	}
+}
+
+func TestParseETagList(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    ETagList
+		wantErr bool
+	}{
+		{value: "*", want: ETagList{Any: true}},
+		{value: `"a,b", W/"c" ,"" ,`, want: ETagList{Tags: []ETag{{Tag: "a,b"}, {Tag: "c", Weak: true}, {Tag: ""}}}},
+		{value: `"a" "b"`, wantErr: true},
+		{value: `abc`, wantErr: true},
+		{value: `"abc`, wantErr: true},
+		{value: `"a b"`, wantErr: true},
+		{value: `*, "a"`, wantErr: true},
+		{value: ` , `, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseETagList(tt.value)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestETagMatch(t *testing.T) {
+	strong, weak := ETag{Tag: "v1"}, ETag{Tag: "v1", Weak: true}
+
+	list := ETagList{Tags: []ETag{weak}}
+	require.False(t, list.MatchStrong(strong))
+	require.True(t, list.MatchWeak(strong))
+
+	list = ETagList{Tags: []ETag{{Tag: "v0"}, strong}}
+	require.True(t, list.MatchStrong(strong))
+	require.False(t, list.MatchStrong(weak))
+	require.False(t, list.MatchWeak(ETag{Tag: "v2"}))
+
+	require.True(t, ETagList{Any: true}.MatchStrong(ETag{Tag: "v2"}))
+
+	etag, err := ParseETag(` W/"v1" `)
+	require.NoError(t, err)
+	require.Equal(t, weak, etag)
+	require.Equal(t, `W/"v1"`, etag.String())
+}
+
+func TestParseIfRange(t *testing.T) {
+	date := time.Date(1994, 11, 6, 8, 49, 37, 0, time.UTC)
+
+	ifRange, err := ParseIfRange(`"v1"`)
+	require.NoError(t, err)
+	require.Equal(t, IfRange{ETag: &ETag{Tag: "v1"}}, ifRange)
+	require.True(t, ifRange.Match(ETag{Tag: "v1"}, date))
+	require.False(t, ifRange.Match(ETag{Tag: "v1", Weak: true}, date))
+	require.Equal(t, `"v1"`, ifRange.String())
+
+	ifRange, err = ParseIfRange("Sun, 06 Nov 1994 08:49:37 GMT")
+	require.NoError(t, err)
+	require.Equal(t, IfRange{Date: date}, ifRange)
+	require.True(t, ifRange.Match(ETag{Tag: "v1"}, date.Add(500*time.Millisecond)))
+	require.False(t, ifRange.Match(ETag{Tag: "v1"}, date.Add(time.Second)))
+	require.False(t, ifRange.Match(ETag{Tag: "v1"}, time.Time{}))
+	require.Equal(t, "Sun, 06 Nov 1994 08:49:37 GMT", ifRange.String())
+
+	for _, value := range []string{`"v1`, "yesterday", ""} {
+		_, err = ParseIfRange(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestDecodeHeaderTypes(t *testing.T) {
+	type DataType struct {
+		Range             *ByteRanges `inreq:"header"`
+		IfMatch           ETagList    `inreq:"header,name=If-Match"`
+		IfNoneMatch       ETagList    `inreq:"header,name=If-None-Match"`
+		IfModifiedSince   time.Time   `inreq:"header,name=If-Modified-Since"`
+		IfUnmodifiedSince *time.Time  `inreq:"header,name=If-Unmodified-Since"`
+		Other             time.Time   `inreq:"header,name=X-Time"`
+		IfRange           IfRange     `inreq:"header,name=If-Range"`
+		IfRangeDate       IfRange     `inreq:"header,name=X-If-Range"`
+		Expires           []string    `inreq:"header"`
+		Dates             []time.Time `inreq:"header,name=X-Dates,layout=httpdate"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Range", "bytes=0-99")
+	r.Header.Set("If-Match", "*")
+	r.Header.Add("If-None-Match", `"a"`)
+	r.Header.Add("If-None-Match", `W/"b"`)
+	r.Header.Set("If-Modified-Since", "Sun, 06 Nov 1994 08:49:37 GMT")
+	r.Header.Set("If-Unmodified-Since", "Sunday, 06-Nov-94 08:49:37 GMT")
+	r.Header.Set("X-Time", "1994-11-06T08:49:37Z")
+	r.Header.Set("If-Range", `"v1"`)
+	r.Header.Set("X-If-Range", "Sun, 06 Nov 1994 08:49:37 GMT")
+	r.Header.Set("Expires", "Sun, 06 Nov 1994 08:49:37 GMT")
+	r.Header.Add("X-Dates", "Sun, 06 Nov 1994 08:49:37 GMT")
+	r.Header.Add("X-Dates", "Sun, 06 Nov 1994 08:49:37 GMT")
+
+	date := time.Date(1994, 11, 6, 8, 49, 37, 0, time.UTC)
+
+	data, err := DecodeType[DataType](r)
+	require.NoError(t, err)
+	require.Equal(t, ByteRanges{{First: 0, Last: 99}}, *data.Range)
+	require.Equal(t, ETagList{Any: true}, data.IfMatch)
+	require.Equal(t, ETagList{Tags: []ETag{{Tag: "a"}, {Tag: "b", Weak: true}}}, data.IfNoneMatch)
+	require.Equal(t, date, data.IfModifiedSince)
+	require.Equal(t, date, *data.IfUnmodifiedSince)
+	require.Equal(t, date, data.Other)
+	require.Equal(t, IfRange{ETag: &ETag{Tag: "v1"}}, data.IfRange)
+	require.Equal(t, IfRange{Date: date}, data.IfRangeDate)
+	require.Equal(t, []string{"Sun, 06 Nov 1994 08:49:37 GMT"}, data.Expires)
+	require.Equal(t, []time.Time{date, date}, data.Dates)
+}
+
+func TestDecodeHeaderTypesError(t *testing.T) {
+	type DataType struct {
+		Range           ByteRanges `inreq:"header"`
+		IfNoneMatch     ETagList   `inreq:"header,name=If-None-Match"`
+		IfModifiedSince time.Time  `inreq:"header,name=If-Modified-Since"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Range", "bytes=10-1")
+	r.Header.Set("If-None-Match", "abc")
+	r.Header.Set("If-Modified-Since", "1994-11-06T08:49:37Z")
+
+	err := Decode(r, &DataType{}, WithCollectErrors(true))
+	var derrs DecodeErrors
+	require.ErrorAs(t, err, &derrs)
+	require.Len(t, derrs, 3)
+	for i, value := range []string{"bytes=10-1", "abc", "1994-11-06T08:49:37Z"} {
+		require.ErrorIs(t, derrs[i], ErrCoerceInvalid)
+		require.Equal(t, value, derrs[i].Value)
+	}
+}
@@ -0,0 +1,2 @@
+// Package jwt provides an inreq "jwt" operation which decodes claims of verified JSON Web Tokens.
+package jwt
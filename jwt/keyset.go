@@ -0,0 +1,192 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Signature algorithms supported by the "jwt" operation (RFC 7518 and RFC 8037).
+const (
+	AlgHS256 = "HS256" // HMAC using SHA-256, with a []byte key.
+	AlgRS256 = "RS256" // RSASSA-PKCS1-v1_5 using SHA-256, with a *rsa.PublicKey key.
+	AlgES256 = "ES256" // ECDSA using P-256 and SHA-256, with a *ecdsa.PublicKey key.
+	AlgEdDSA = "EdDSA" // EdDSA using Ed25519, with an ed25519.PublicKey key.
+)
+
+// ErrKeyNotFound is returned by a KeySet when no key matches the token.
+var ErrKeyNotFound = errors.New("key not found")
+
+// KeySet returns the keys used to verify token signatures.
+type KeySet interface {
+	// Key returns the key for the "kid" (which may be blank) and "alg" token header parameters.
+	// The key type must match the algorithm, like []byte for HS256 or *rsa.PublicKey for RS256.
+	Key(kid string, alg string) (any, error)
+}
+
+// KeySetFunc is a function adapter for KeySet.
+type KeySetFunc func(kid string, alg string) (any, error)
+
+func (f KeySetFunc) Key(kid string, alg string) (any, error) {
+	return f(kid, alg)
+}
+
+// StaticKey returns a KeySet which always returns the same key, ignoring the "kid" header parameter.
+func StaticKey(key any) KeySet {
+	return KeySetFunc(func(kid string, alg string) (any, error) {
+		if !keyMatchesAlg(key, alg) {
+			return nil, fmt.Errorf("%w for algorithm '%s'", ErrKeyNotFound, alg)
+		}
+		return key, nil
+	})
+}
+
+// JWKS is a static KeySet from a JSON Web Key Set document (RFC 7517).
+// Keys of unsupported types and keys not used for signatures are ignored.
+type JWKS struct {
+	keys []jwksKey
+}
+
+type jwksKey struct {
+	kid string
+	alg string
+	key any
+}
+
+// jwk is a JSON Web Key. Only the members of the supported key types are declared.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	K   string `json:"k"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS parses a JSON Web Key Set document, like `{"keys": [{"kty": "RSA", "kid": "k1", "n": "...", "e": "AQAB"}]}`.
+// Supported key types are "oct" (HS256), "RSA" (RS256), "EC" with the "P-256" curve (ES256) and "OKP" with the
+// "Ed25519" curve (EdDSA).
+func ParseJWKS(data []byte) (*JWKS, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid JWKS document: %w", err)
+	}
+
+	ret := &JWKS{}
+	for i, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := parseJWK(k)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWKS key %d: %w", i, err)
+		}
+		if key != nil {
+			ret.keys = append(ret.keys, jwksKey{kid: k.Kid, alg: k.Alg, key: key})
+		}
+	}
+	return ret, nil
+}
+
+// Key returns the first key matching the "kid" and "alg" token header parameters. If the token doesn't have a "kid",
+// any key compatible with the algorithm matches.
+func (s *JWKS) Key(kid string, alg string) (any, error) {
+	for _, k := range s.keys {
+		if (kid != "" && k.kid != kid) || (k.alg != "" && k.alg != alg) || !keyMatchesAlg(k.key, alg) {
+			continue
+		}
+		return k.key, nil
+	}
+	return nil, fmt.Errorf("%w for kid '%s' and algorithm '%s'", ErrKeyNotFound, kid, alg)
+}
+
+// parseJWK returns the key, or nil if the key type is not supported.
+func parseJWK(k jwk) (any, error) {
+	switch k.Kty {
+	case "oct":
+		return decodeJWKMember("k", k.K)
+	case "RSA":
+		n, err := decodeJWKMember("n", k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeJWKMember("e", k.E)
+		if err != nil {
+			return nil, err
+		}
+		exp := new(big.Int).SetBytes(e)
+		if !exp.IsInt64() || exp.Int64() < 2 || exp.Int64() > 1<<31-1 {
+			return nil, errors.New("invalid RSA exponent")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, nil
+		}
+		x, err := decodeJWKMember("x", k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeJWKMember("y", k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if len(x) != 32 || len(y) != 32 || !key.Curve.IsOnCurve(key.X, key.Y) {
+			return nil, errors.New("invalid P-256 point")
+		}
+		return key, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, nil
+		}
+		x, err := decodeJWKMember("x", k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, nil
+}
+
+func decodeJWKMember(name string, value string) ([]byte, error) {
+	if value == "" {
+		return nil, fmt.Errorf("missing '%s' member", name)
+	}
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid '%s' member: %w", name, err)
+	}
+	return b, nil
+}
+
+// keyMatchesAlg returns whether the key type can be used with the algorithm. This prevents algorithm confusion,
+// like verifying an HS256 token using a public key as the secret.
+func keyMatchesAlg(key any, alg string) bool {
+	switch k := key.(type) {
+	case []byte:
+		return alg == AlgHS256 && len(k) > 0
+	case *rsa.PublicKey:
+		return alg == AlgRS256
+	case *ecdsa.PublicKey:
+		return alg == AlgES256 && k.Curve == elliptic.P256()
+	case ed25519.PublicKey:
+		return alg == AlgEdDSA && len(k) == ed25519.PublicKeySize
+	}
+	return false
+}
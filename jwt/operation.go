@@ -0,0 +1,233 @@
+package jwt
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/rrgmc/inreq"
+	"github.com/rrgmc/instruct/types"
+)
+
+// OperationJWT is the name of the "jwt" operation.
+const OperationJWT = "jwt"
+
+// DecodeOperation is an inreq.DecodeOperation that gets claims from a verified JSON Web Token (RFC 7519).
+// The claim set in the "claim" tag option is unmarshaled into the field, or all claims if it is not set, like into
+// a struct embedding RegisteredClaims. Missing (if required) or invalid tokens return an inreq.UnauthorizedError
+// for the "Bearer" scheme, which wraps one of the token errors, like ErrTokenExpired.
+type DecodeOperation struct {
+	keySet     KeySet
+	sources    []TokenSource
+	algorithms []string
+	issuers    []string
+	audiences  []string
+	leeway     time.Duration
+	realm      string
+	now        func() time.Time
+}
+
+// tokenKey is the inreq.DecodeContextValues key of the token verified by a DecodeOperation, so multiple claim
+// fields of the same request don't verify it again.
+type tokenKey struct {
+	operation *DecodeOperation
+}
+
+// NewDecodeOperation creates a "jwt" DecodeOperation verifying tokens with the keys of the KeySet.
+func NewDecodeOperation(keySet KeySet, options ...Option) *DecodeOperation {
+	ret := &DecodeOperation{
+		keySet:     keySet,
+		sources:    []TokenSource{FromAuthorization()},
+		algorithms: []string{AlgHS256, AlgRS256, AlgES256, AlgEdDSA},
+		now:        time.Now,
+	}
+	for _, opt := range options {
+		opt(ret)
+	}
+	return ret
+}
+
+// WithDecodeOperation returns an option which adds a "jwt" DecodeOperation to inreq decoders.
+func WithDecodeOperation(keySet KeySet, options ...Option) inreq.DefaultAndTypeDefaultOption {
+	return inreq.WithDecodeOperation(OperationJWT, NewDecodeOperation(keySet, options...))
+}
+
+func (d *DecodeOperation) Decode(ctx inreq.DecodeContext, r *http.Request, isList bool, field reflect.Value,
+	tag *inreq.Tag) (bool, any, error) {
+	raw, ok := d.token(r)
+	if !ok {
+		return false, nil, nil
+	}
+
+	t, err := d.verify(ctx, raw)
+	if err != nil {
+		return false, nil, d.unauthorizedError(tag, err)
+	}
+
+	data := t.payload
+	if claim, ok := tag.Options.Get("claim"); ok {
+		if data, ok = t.claims[claim]; !ok {
+			return false, nil, nil
+		}
+	}
+
+	// the resolver handles pointer fields, so the value is unmarshaled into the element type.
+	typ := field.Type()
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	value := reflect.New(typ)
+	if err = json.Unmarshal(data, value.Interface()); err != nil {
+		return false, nil, types.NewCoerceError(fmt.Errorf("%w: %w", inreq.ErrCoerceInvalid, err))
+	}
+	return true, value.Elem().Interface(), nil
+}
+
+// IsSensitive returns true, as claims usually contain personal data.
+func (d *DecodeOperation) IsSensitive(tag *inreq.Tag) bool {
+	return true
+}
+
+// WrapRequiredError returns an inreq.UnauthorizedError for missing tokens.
+func (d *DecodeOperation) WrapRequiredError(tag *inreq.Tag, err inreq.RequiredError) error {
+	return d.unauthorizedError(tag, err)
+}
+
+// token returns the token from the first TokenSource which has one.
+func (d *DecodeOperation) token(r *http.Request) (string, bool) {
+	for _, source := range d.sources {
+		if raw, ok := source(r); ok && raw != "" {
+			return raw, true
+		}
+	}
+	return "", false
+}
+
+// verify returns the verified token, checking its claims every time as they depend on the current time.
+// The token is only parsed once per request, as it is stored in the decode context.
+func (d *DecodeOperation) verify(ctx inreq.DecodeContext, raw string) (*token, error) {
+	values, _ := ctx.(inreq.DecodeContextValues)
+	var t *token
+	if values != nil {
+		t, _ = values.Value(tokenKey{d}).(*token)
+	}
+	if t == nil || t.raw != raw {
+		var err error
+		if t, err = parseToken(raw, d.keySet, d.algorithms); err != nil {
+			return nil, err
+		}
+		if values != nil {
+			values.SetValue(tokenKey{d}, t)
+		}
+	}
+	if err := d.validateClaims(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (d *DecodeOperation) unauthorizedError(tag *inreq.Tag, err error) inreq.UnauthorizedError {
+	return inreq.UnauthorizedError{
+		Scheme: inreq.AuthSchemeBearer,
+		Realm:  tag.Options.Value("realm", d.realm),
+		Err:    err,
+	}
+}
+
+// Option is an option for NewDecodeOperation.
+type Option func(*DecodeOperation)
+
+// WithTokenSource sets where the token is read from, trying each source in order.
+// The default is FromAuthorization.
+func WithTokenSource(sources ...TokenSource) Option {
+	return func(o *DecodeOperation) {
+		o.sources = sources
+	}
+}
+
+// WithAlgorithms sets the allowed signature algorithms. The default is all supported algorithms.
+func WithAlgorithms(algorithms ...string) Option {
+	return func(o *DecodeOperation) {
+		o.algorithms = algorithms
+	}
+}
+
+// WithIssuer requires the "iss" claim to be one of the issuers.
+func WithIssuer(issuers ...string) Option {
+	return func(o *DecodeOperation) {
+		o.issuers = issuers
+	}
+}
+
+// WithAudience requires the "aud" claim to contain one of the audiences.
+func WithAudience(audiences ...string) Option {
+	return func(o *DecodeOperation) {
+		o.audiences = audiences
+	}
+}
+
+// WithLeeway sets the allowed clock skew when checking the "exp" and "nbf" claims.
+func WithLeeway(leeway time.Duration) Option {
+	return func(o *DecodeOperation) {
+		o.leeway = leeway
+	}
+}
+
+// WithRealm sets the default realm of the challenge of errors. The "realm" tag option takes precedence.
+func WithRealm(realm string) Option {
+	return func(o *DecodeOperation) {
+		o.realm = realm
+	}
+}
+
+// WithNow sets the function returning the current time, used to check the "exp" and "nbf" claims.
+func WithNow(now func() time.Time) Option {
+	return func(o *DecodeOperation) {
+		o.now = now
+	}
+}
+
+// TokenSource returns the token of the request, or false if it doesn't have one.
+type TokenSource func(r *http.Request) (string, bool)
+
+// FromAuthorization returns the token of the "Bearer" scheme of the "Authorization" header.
+// Other schemes are considered as not having a token.
+func FromAuthorization() TokenSource {
+	return func(r *http.Request) (string, bool) {
+		scheme, raw, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !strings.EqualFold(scheme, inreq.AuthSchemeBearer) {
+			return "", false
+		}
+		return strings.Trim(raw, " "), true
+	}
+}
+
+// FromHeader returns the whole value of the header as the token.
+func FromHeader(name string) TokenSource {
+	return func(r *http.Request) (string, bool) {
+		raw := r.Header.Get(name)
+		return raw, raw != ""
+	}
+}
+
+// FromCookie returns the value of the cookie as the token.
+func FromCookie(name string) TokenSource {
+	return func(r *http.Request) (string, bool) {
+		cookie, err := r.Cookie(name)
+		if err != nil {
+			return "", false
+		}
+		return cookie.Value, true
+	}
+}
+
+// FromQuery returns the value of the query parameter as the token.
+func FromQuery(name string) TokenSource {
+	return func(r *http.Request) (string, bool) {
+		raw := r.URL.Query().Get(name)
+		return raw, raw != ""
+	}
+}
@@ -0,0 +1,385 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rrgmc/inreq"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+type testKeys struct {
+	hmac    []byte
+	rsa     *rsa.PrivateKey
+	ecdsa   *ecdsa.PrivateKey
+	ed25519 ed25519.PrivateKey
+}
+
+func newTestKeys(t *testing.T) testKeys {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, ed25519Key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return testKeys{hmac: []byte("secret-key"), rsa: rsaKey, ecdsa: ecdsaKey, ed25519: ed25519Key}
+}
+
+func (k testKeys) jwks(t *testing.T) []byte {
+	b64 := base64.RawURLEncoding.EncodeToString
+	pad := func(i *big.Int) string {
+		return b64(i.FillBytes(make([]byte, 32)))
+	}
+	doc, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "oct", "kid": "hs", "k": b64(k.hmac)},
+		{"kty": "RSA", "kid": "rs", "n": b64(k.rsa.N.Bytes()), "e": "AQAB"},
+		{"kty": "EC", "kid": "es", "crv": "P-256", "x": pad(k.ecdsa.X), "y": pad(k.ecdsa.Y)},
+		{"kty": "OKP", "kid": "ed", "crv": "Ed25519", "x": b64(k.ed25519.Public().(ed25519.PublicKey))},
+		{"kty": "RSA", "kid": "enc", "use": "enc", "n": "invalid"},
+		{"kty": "unknown", "kid": "unknown"},
+	}})
+	require.NoError(t, err)
+	return doc
+}
+
+func (k testKeys) sign(t *testing.T, alg string, kid string, claims any) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+	input := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	h := sha256.Sum256([]byte(input))
+	var signature []byte
+	switch alg {
+	case AlgHS256:
+		mac := hmac.New(sha256.New, k.hmac)
+		mac.Write([]byte(input))
+		signature = mac.Sum(nil)
+	case AlgRS256:
+		signature, err = rsa.SignPKCS1v15(rand.Reader, k.rsa, crypto.SHA256, h[:])
+		require.NoError(t, err)
+	case AlgES256:
+		r, s, err := ecdsa.Sign(rand.Reader, k.ecdsa, h[:])
+		require.NoError(t, err)
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case AlgEdDSA:
+		signature = ed25519.Sign(k.ed25519, []byte(input))
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+type testClaims struct {
+	RegisteredClaims
+	Email string   `json:"email"`
+	Roles []string `json:"roles"`
+}
+
+func TestDecodeJWT(t *testing.T) {
+	keys := newTestKeys(t)
+	jwks, err := ParseJWKS(keys.jwks(t))
+	require.NoError(t, err)
+
+	claims := map[string]any{
+		"sub":   "user1",
+		"iss":   "issuer",
+		"aud":   "api",
+		"exp":   testNow.Add(time.Hour).Unix(),
+		"nbf":   testNow.Add(-time.Hour).Unix(),
+		"email": "user1@example.com",
+		"roles": []string{"admin", "user"},
+	}
+
+	type DataType struct {
+		Subject string              `inreq:"jwt,claim=sub"`
+		Roles   []string            `inreq:"jwt,claim=roles"`
+		Missing inreq.Optional[int] `inreq:"jwt,claim=missing"`
+		Claims  *testClaims         `inreq:"jwt"`
+	}
+
+	for _, alg := range []string{AlgHS256, AlgRS256, AlgES256, AlgEdDSA} {
+		t.Run(alg, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Authorization", "Bearer "+keys.sign(t, alg, "", claims))
+
+			data, err := inreq.DecodeType[DataType](r,
+				WithDecodeOperation(jwks, WithIssuer("issuer"), WithAudience("other", "api"),
+					WithNow(func() time.Time { return testNow })))
+			require.NoError(t, err)
+			require.Equal(t, "user1", data.Subject)
+			require.Equal(t, []string{"admin", "user"}, data.Roles)
+			require.False(t, data.Missing.IsSet())
+			require.Equal(t, "user1@example.com", data.Claims.Email)
+			require.Equal(t, Audience{"api"}, data.Claims.Audience)
+			require.Equal(t, testNow.Add(time.Hour), data.Claims.ExpiresAt.UTC())
+		})
+	}
+}
+
+func TestDecodeJWTKeyLookup(t *testing.T) {
+	keys := newTestKeys(t)
+
+	type DataType struct {
+		Subject string `inreq:"jwt,claim=sub"`
+		Email   string `inreq:"jwt,claim=email"`
+	}
+
+	// the key is revoked after the first request.
+	lookups := 0
+	keySet := KeySetFunc(func(kid string, alg string) (any, error) {
+		lookups++
+		if lookups > 1 {
+			return nil, ErrKeyNotFound
+		}
+		return keys.hmac, nil
+	})
+	dec := inreq.NewTypeDecoder[DataType](WithDecodeOperation(keySet,
+		WithNow(func() time.Time { return testNow })))
+
+	token := keys.sign(t, AlgHS256, "", map[string]any{"sub": "user1", "email": "user1@example.com"})
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	data, err := dec.Decode(r)
+	require.NoError(t, err)
+	require.Equal(t, DataType{Subject: "user1", Email: "user1@example.com"}, data)
+	require.Equal(t, 1, lookups)
+
+	_, err = dec.Decode(r)
+	require.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestDecodeJWTTokenSource(t *testing.T) {
+	keys := newTestKeys(t)
+	token := keys.sign(t, AlgHS256, "", map[string]any{"sub": "user1"})
+
+	tests := []struct {
+		name    string
+		request func(r *http.Request)
+	}{
+		{
+			name: "cookie",
+			request: func(r *http.Request) {
+				r.AddCookie(&http.Cookie{Name: "session", Value: token})
+			},
+		},
+		{
+			name: "query",
+			request: func(r *http.Request) {
+				r.URL.RawQuery = "access_token=" + token
+			},
+		},
+		{
+			name: "authorization fallback",
+			request: func(r *http.Request) {
+				r.Header.Set("Authorization", "Bearer "+token)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			tt.request(r)
+
+			data, err := inreq.DecodeType[struct {
+				Subject string `inreq:"jwt,claim=sub"`
+			}](r, WithDecodeOperation(StaticKey(keys.hmac),
+				WithTokenSource(FromCookie("session"), FromQuery("access_token"), FromAuthorization())))
+			require.NoError(t, err)
+			require.Equal(t, "user1", data.Subject)
+		})
+	}
+}
+
+func TestDecodeJWTError(t *testing.T) {
+	keys := newTestKeys(t)
+	otherKeys := newTestKeys(t)
+	jwks, err := ParseJWKS(keys.jwks(t))
+	require.NoError(t, err)
+
+	valid := map[string]any{"sub": "user1", "iss": "issuer", "aud": []string{"api"}}
+
+	tests := []struct {
+		name          string
+		token         string
+		wantErr       error
+		wantChallenge string
+	}{
+		{
+			name:          "missing",
+			wantChallenge: `Bearer realm="api"`,
+		},
+		{
+			name:    "malformed",
+			token:   "abc.def",
+			wantErr: ErrTokenMalformed,
+		},
+		{
+			name:    "invalid signature",
+			token:   otherKeys.sign(t, AlgRS256, "rs", valid),
+			wantErr: ErrTokenSignatureInvalid,
+		},
+		{
+			name:    "algorithm none",
+			token:   keys.sign(t, "none", "", valid),
+			wantErr: ErrTokenUnverifiable,
+		},
+		{
+			name:    "algorithm not allowed",
+			token:   keys.sign(t, AlgEdDSA, "", valid),
+			wantErr: ErrTokenUnverifiable,
+		},
+		{
+			name:    "key algorithm mismatch",
+			token:   keys.sign(t, AlgHS256, "rs", valid),
+			wantErr: ErrKeyNotFound,
+		},
+		{
+			name:    "expired",
+			token:   keys.sign(t, AlgRS256, "rs", merge(valid, map[string]any{"exp": testNow.Add(-time.Minute).Unix()})),
+			wantErr: ErrTokenExpired,
+		},
+		{
+			name:    "not valid yet",
+			token:   keys.sign(t, AlgES256, "es", merge(valid, map[string]any{"nbf": testNow.Add(time.Minute).Unix()})),
+			wantErr: ErrTokenNotValidYet,
+		},
+		{
+			name:    "invalid issuer",
+			token:   keys.sign(t, AlgHS256, "hs", merge(valid, map[string]any{"iss": "other"})),
+			wantErr: ErrTokenInvalidIssuer,
+		},
+		{
+			name:    "missing audience",
+			token:   keys.sign(t, AlgHS256, "hs", merge(valid, map[string]any{"aud": nil})),
+			wantErr: ErrTokenInvalidAudience,
+		},
+		{
+			name:    "invalid exp",
+			token:   keys.sign(t, AlgHS256, "hs", merge(valid, map[string]any{"exp": "tomorrow"})),
+			wantErr: ErrTokenMalformed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.token != "" {
+				r.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+
+			_, err := inreq.DecodeType[struct {
+				Subject string `inreq:"jwt,claim=sub"`
+			}](r, WithDecodeOperation(jwks, WithIssuer("issuer"), WithAudience("api"), WithRealm("api"),
+				WithAlgorithms(AlgHS256, AlgRS256, AlgES256), WithLeeway(time.Second),
+				WithNow(func() time.Time { return testNow })))
+			if tt.wantErr == nil {
+				require.ErrorAs(t, err, &inreq.RequiredError{})
+			} else {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+
+			var uerr inreq.UnauthorizedError
+			require.ErrorAs(t, err, &uerr)
+			wantChallenge := tt.wantChallenge
+			if wantChallenge == "" {
+				wantChallenge = `Bearer realm="api", error="invalid_token"`
+			}
+			require.Equal(t, wantChallenge, uerr.Challenge())
+			if tt.token != "" {
+				require.NotContains(t, err.Error(), tt.token)
+			}
+		})
+	}
+}
+
+func TestDecodeJWTClaimTypeError(t *testing.T) {
+	keys := newTestKeys(t)
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+keys.sign(t, AlgHS256, "", map[string]any{"sub": "user1"}))
+
+	_, err := inreq.DecodeType[struct {
+		Subject int `inreq:"jwt,claim=sub"`
+	}](r, WithDecodeOperation(StaticKey(keys.hmac)))
+	require.ErrorIs(t, err, inreq.ErrCoerceInvalid)
+	require.False(t, errors.As(err, &inreq.UnauthorizedError{}))
+
+}
+
+func TestDecodeJWTSensitive(t *testing.T) {
+	keys := newTestKeys(t)
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+keys.sign(t, AlgHS256, "", map[string]any{"sub": "user1"}))
+
+	// claims are sensitive even if the ValueRedactor doesn't redact them.
+	_, err := inreq.DecodeType[struct {
+		Subject string `inreq:"jwt,claim=sub,max=3"`
+	}](r, WithDecodeOperation(StaticKey(keys.hmac)), inreq.WithValueRedactor(func(string, string) bool {
+		return false
+	}))
+	var ferr inreq.FieldError
+	require.ErrorAs(t, err, &ferr)
+	require.True(t, ferr.Redacted)
+	require.Equal(t, inreq.RedactedValue, ferr.Value)
+
+	_, err = inreq.DecodeType[struct {
+		Subject string `inreq:"jwt,claim=sub,max=3,sensitive=false"`
+	}](r, WithDecodeOperation(StaticKey(keys.hmac)))
+	require.ErrorAs(t, err, &ferr)
+	require.Equal(t, "user1", ferr.Value)
+}
+
+func TestParseJWKSError(t *testing.T) {
+	for _, doc := range []string{
+		`{"keys": {}}`,
+		`{"keys": [{"kty": "RSA", "n": "AQAB"}]}`,
+		`{"keys": [{"kty": "EC", "crv": "P-256", "x": "AQAB", "y": "AQAB"}]}`,
+		`{"keys": [{"kty": "OKP", "crv": "Ed25519", "x": "AQAB"}]}`,
+		`{"keys": [{"kty": "oct", "k": "!"}]}`,
+	} {
+		t.Run(doc, func(t *testing.T) {
+			_, err := ParseJWKS([]byte(doc))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestNumericDate(t *testing.T) {
+	var d NumericDate
+	require.NoError(t, json.Unmarshal([]byte(`1700000000.5`), &d))
+	require.Equal(t, time.Unix(1700000000, 5e8), d.Time)
+	b, err := json.Marshal(d)
+	require.NoError(t, err)
+	require.Equal(t, "1700000000", string(b))
+	require.Error(t, json.Unmarshal([]byte(`"x"`), &d))
+	require.Error(t, json.Unmarshal([]byte(fmt.Sprint(float64(1<<60))), &d))
+}
+
+func merge(m1, m2 map[string]any) map[string]any {
+	ret := map[string]any{}
+	for k, v := range m1 {
+		ret[k] = v
+	}
+	for k, v := range m2 {
+		ret[k] = v
+	}
+	return ret
+}
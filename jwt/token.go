@@ -0,0 +1,211 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Token errors, wrapped in the inreq.UnauthorizedError returned by the "jwt" operation. They never contain the token.
+var (
+	ErrTokenMalformed        = errors.New("malformed token")
+	ErrTokenUnverifiable     = errors.New("token is unverifiable")
+	ErrTokenSignatureInvalid = errors.New("token signature is invalid")
+	ErrTokenExpired          = errors.New("token is expired")
+	ErrTokenNotValidYet      = errors.New("token is not valid yet")
+	ErrTokenInvalidAudience  = errors.New("token has invalid audience")
+	ErrTokenInvalidIssuer    = errors.New("token has invalid issuer")
+)
+
+// NumericDate is a JWT date, encoded as the number of seconds since the epoch.
+type NumericDate struct {
+	time.Time
+}
+
+func (d *NumericDate) UnmarshalJSON(data []byte) error {
+	var f float64
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("invalid numeric date: %w", err)
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) || math.Abs(f) > 1<<53 {
+		return errors.New("invalid numeric date")
+	}
+	sec, frac := math.Modf(f)
+	d.Time = time.Unix(int64(sec), int64(frac*1e9))
+	return nil
+}
+
+func (d NumericDate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Unix())
+}
+
+// Audience is the "aud" claim, which can be a single string or an array of strings.
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*a = Audience{s}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return errors.New("invalid audience")
+	}
+	*a = list
+	return nil
+}
+
+// RegisteredClaims are the registered claims of RFC 7519, section 4.1. They can be embedded in claims structs.
+type RegisteredClaims struct {
+	Issuer    string       `json:"iss,omitempty"`
+	Subject   string       `json:"sub,omitempty"`
+	Audience  Audience     `json:"aud,omitempty"`
+	ExpiresAt *NumericDate `json:"exp,omitempty"`
+	NotBefore *NumericDate `json:"nbf,omitempty"`
+	IssuedAt  *NumericDate `json:"iat,omitempty"`
+	ID        string       `json:"jti,omitempty"`
+}
+
+// token is a verified token.
+type token struct {
+	raw     string
+	payload []byte
+	claims  map[string]json.RawMessage
+	rc      RegisteredClaims
+}
+
+type tokenHeader struct {
+	Alg  string   `json:"alg"`
+	Kid  string   `json:"kid"`
+	Crit []string `json:"crit"`
+}
+
+// parseToken parses a JWS compact serialization token and verifies its signature.
+func parseToken(raw string, keySet KeySet, algorithms []string) (*token, error) {
+	parts := strings.Split(raw, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: expected 3 parts", ErrTokenMalformed)
+	}
+
+	var header tokenHeader
+	if err := decodeTokenPart(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("%w: invalid header: %w", ErrTokenMalformed, err)
+	}
+	if len(header.Crit) > 0 {
+		return nil, fmt.Errorf("%w: unsupported critical header parameters", ErrTokenUnverifiable)
+	}
+	if !containsString(algorithms, header.Alg) {
+		return nil, fmt.Errorf("%w: algorithm '%s' is not allowed", ErrTokenUnverifiable, header.Alg)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid signature encoding", ErrTokenMalformed)
+	}
+
+	key, err := keySet.Key(header.Kid, header.Alg)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTokenUnverifiable, err)
+	}
+	if !keyMatchesAlg(key, header.Alg) {
+		return nil, fmt.Errorf("%w: invalid key type for algorithm '%s'", ErrTokenUnverifiable, header.Alg)
+	}
+	if !verifySignature(header.Alg, key, raw[:len(parts[0])+1+len(parts[1])], signature) {
+		return nil, ErrTokenSignatureInvalid
+	}
+
+	ret := &token{raw: raw}
+	if ret.payload, err = base64.RawURLEncoding.DecodeString(parts[1]); err != nil {
+		return nil, fmt.Errorf("%w: invalid payload encoding", ErrTokenMalformed)
+	}
+	if err = json.Unmarshal(ret.payload, &ret.claims); err != nil || ret.claims == nil {
+		return nil, fmt.Errorf("%w: claims must be a JSON object", ErrTokenMalformed)
+	}
+	if err = json.Unmarshal(ret.payload, &ret.rc); err != nil {
+		return nil, fmt.Errorf("%w: invalid registered claims", ErrTokenMalformed)
+	}
+	return ret, nil
+}
+
+func decodeTokenPart(part string, v any) error {
+	b, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// verifySignature verifies the signature of the signing input. The key type was already checked.
+func verifySignature(alg string, key any, input string, signature []byte) bool {
+	switch alg {
+	case AlgHS256:
+		mac := hmac.New(sha256.New, key.([]byte))
+		mac.Write([]byte(input))
+		return hmac.Equal(mac.Sum(nil), signature)
+	case AlgRS256:
+		h := sha256.Sum256([]byte(input))
+		return rsa.VerifyPKCS1v15(key.(*rsa.PublicKey), crypto.SHA256, h[:], signature) == nil
+	case AlgES256:
+		// the signature is the concatenation of the 32-byte big-endian r and s values (RFC 7518, section 3.4).
+		if len(signature) != 64 {
+			return false
+		}
+		h := sha256.Sum256([]byte(input))
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		return ecdsa.Verify(key.(*ecdsa.PublicKey), h[:], r, s)
+	case AlgEdDSA:
+		return ed25519.Verify(key.(ed25519.PublicKey), []byte(input), signature)
+	}
+	return false
+}
+
+// validateClaims checks the "exp", "nbf", "iss" and "aud" claims. The issuer and audience are only checked if
+// configured, in which case the claims are required.
+func (d *DecodeOperation) validateClaims(t *token) error {
+	now := d.now()
+	if t.rc.ExpiresAt != nil && !now.Before(t.rc.ExpiresAt.Add(d.leeway)) {
+		return ErrTokenExpired
+	}
+	if t.rc.NotBefore != nil && now.Add(d.leeway).Before(t.rc.NotBefore.Time) {
+		return ErrTokenNotValidYet
+	}
+	if len(d.issuers) > 0 && !containsString(d.issuers, t.rc.Issuer) {
+		return ErrTokenInvalidIssuer
+	}
+	if len(d.audiences) > 0 {
+		found := false
+		for _, aud := range t.rc.Audience {
+			if containsString(d.audiences, aud) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return ErrTokenInvalidAudience
+		}
+	}
+	return nil
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
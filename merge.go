@@ -0,0 +1,140 @@
+package inreq
+
+import (
+	"reflect"
+	"strings"
+)
+
+// MergeMode sets how decoded values are set on fields which already contain a value, like in structs pre-filled from
+// configuration or defaults. Fields which are not found in the request always keep their values.
+type MergeMode int
+
+const (
+	// MergeModeDefault sets the values directly on the fields. Resolved values replace the field value, and the
+	// body is unmarshaled over the existing value, using the semantics of the BodyDecoder.
+	MergeModeDefault MergeMode = iota
+	// MergeModeReplace replaces the whole field value with the decoded one, including slices, maps and body structs.
+	MergeModeReplace
+	// MergeModeMerge merges the decoded value into the field value: slices are appended, map keys are added or
+	// replaced, and struct fields (like in the body) are merged recursively, where zero values don't replace
+	// existing ones, unless they were present in a JSON body (see BodyFields). Other values are replaced. The
+	// existing values are never modified in place, so they can be shared between requests.
+	MergeModeMerge
+	// MergeModeOnlyZero only sets the decoded value if the field has the zero value.
+	MergeModeOnlyZero
+)
+
+// mergeFieldValue sets the decoded value src on the field dst according to the merge mode. For MergeModeOnlyZero,
+// the caller must check if the field was zero before decoding.
+// For MergeModeMerge, fields are the JSON pointers present in the body the value was decoded from, if any, so zero
+// values which were sent replace the existing ones.
+func mergeFieldValue(dst, src reflect.Value, mode MergeMode, fields BodyFields) {
+	if mode == MergeModeMerge {
+		mergeValue(dst, src, true, fields, "")
+		return
+	}
+	dst.Set(src)
+}
+
+// mergeValue merges src into dst. Zero values are only set on the top level, or if the JSON pointer is present in
+// the body fields. Slices and maps sent as null are cleared.
+func mergeValue(dst, src reflect.Value, top bool, fields BodyFields, pointer string) {
+	set := top || fields.Has(pointer)
+	switch dst.Kind() {
+	case reflect.Slice:
+		if src.Len() == 0 {
+			if (top && dst.Len() == 0) || (!top && set && src.IsNil()) {
+				dst.Set(src)
+			}
+			return
+		}
+		if dst.Len() == 0 {
+			dst.Set(src)
+			return
+		}
+		// create a new slice, as the existing one may be shared.
+		ret := reflect.MakeSlice(dst.Type(), 0, dst.Len()+src.Len())
+		ret = reflect.AppendSlice(ret, dst)
+		dst.Set(reflect.AppendSlice(ret, src))
+	case reflect.Map:
+		if src.Len() == 0 {
+			if (top && dst.Len() == 0) || (!top && set && src.IsNil()) {
+				dst.Set(src)
+			}
+			return
+		}
+		if dst.Len() == 0 {
+			dst.Set(src)
+			return
+		}
+		// create a new map, as the existing one may be shared.
+		ret := reflect.MakeMapWithSize(dst.Type(), dst.Len()+src.Len())
+		for _, m := range []reflect.Value{dst, src} {
+			iter := m.MapRange()
+			for iter.Next() {
+				ret.SetMapIndex(iter.Key(), iter.Value())
+			}
+		}
+		dst.Set(ret)
+	case reflect.Pointer:
+		if src.IsNil() {
+			if set && !top {
+				dst.Set(src)
+			}
+			return
+		}
+		if dst.IsNil() {
+			dst.Set(src)
+			return
+		}
+		ret := reflect.New(dst.Type().Elem())
+		ret.Elem().Set(dst.Elem())
+		mergeValue(ret.Elem(), src.Elem(), top, fields, pointer)
+		dst.Set(ret)
+	case reflect.Struct:
+		if !isMergeableStruct(dst.Type()) {
+			// structs with unexported fields (like time.Time and Optional) are set as a single value.
+			if set || !src.IsZero() {
+				dst.Set(src)
+			}
+			return
+		}
+		for i := 0; i < dst.NumField(); i++ {
+			fpointer, ok := jsonFieldPointer(pointer, dst.Type().Field(i), fields)
+			if !ok {
+				// fields not decoded from JSON are not present in the body fields.
+				fields, fpointer = nil, ""
+			}
+			mergeValue(dst.Field(i), src.Field(i), false, fields, fpointer)
+		}
+	default:
+		if set || !src.IsZero() {
+			dst.Set(src)
+		}
+	}
+}
+
+// jsonFieldPointer returns the JSON pointer of the struct field in the body fields, using the "json" struct tag name.
+// Embedded structs without a name are at the same level as the parent. Returns false for fields ignored by JSON.
+func jsonFieldPointer(parent string, field reflect.StructField, fields BodyFields) (string, bool) {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	switch {
+	case name == "-":
+		return "", false
+	case name == "" && field.Anonymous:
+		return parent, true
+	case name == "":
+		name = field.Name
+	}
+	return fields.fieldPointer(parent, name), true
+}
+
+// isMergeableStruct returns whether all struct fields are exported.
+func isMergeableStruct(typ reflect.Type) bool {
+	for i := 0; i < typ.NumField(); i++ {
+		if !typ.Field(i).IsExported() {
+			return false
+		}
+	}
+	return true
+}
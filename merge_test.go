@@ -0,0 +1,239 @@
+package inreq
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeMergeMode(t *testing.T) {
+	type Body struct {
+		Name   string            `json:"name"`
+		Count  int               `json:"count"`
+		Tags   []string          `json:"tags"`
+		Labels map[string]string `json:"labels"`
+	}
+
+	type Paging struct {
+		Page  int `inreq:"query"`
+		Limit int `inreq:"query"`
+	}
+
+	type DataType struct {
+		Tags   []string `inreq:"query,name=tag"`
+		Sort   string   `inreq:"query"`
+		Region string   `inreq:"header,name=X-Region"`
+		Paging Paging   `inreq:"recurse"`
+		Body   Body     `inreq:"body"`
+	}
+
+	newDefaults := func() DataType {
+		return DataType{
+			Tags:   []string{"default"},
+			Region: "us",
+			Paging: Paging{Limit: 10},
+			Body: Body{
+				Name:   "default",
+				Count:  1,
+				Tags:   []string{"b1"},
+				Labels: map[string]string{"a": "1", "b": "2"},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		mergeMode MergeMode
+		want      DataType
+	}{
+		{
+			name:      "default",
+			mergeMode: MergeModeDefault,
+			want: DataType{
+				Tags:   []string{"t1", "t2"},
+				Sort:   "asc",
+				Region: "us",
+				Paging: Paging{Page: 2, Limit: 10},
+				Body: Body{
+					Name:   "request",
+					Count:  1,
+					Tags:   []string{"b2"},
+					Labels: map[string]string{"a": "1", "b": "3"},
+				},
+			},
+		},
+		{
+			name:      "replace",
+			mergeMode: MergeModeReplace,
+			want: DataType{
+				Tags:   []string{"t1", "t2"},
+				Sort:   "asc",
+				Region: "us",
+				Paging: Paging{Page: 2, Limit: 10},
+				Body: Body{
+					Name:   "request",
+					Tags:   []string{"b2"},
+					Labels: map[string]string{"b": "3"},
+				},
+			},
+		},
+		{
+			name:      "merge",
+			mergeMode: MergeModeMerge,
+			want: DataType{
+				Tags:   []string{"default", "t1", "t2"},
+				Sort:   "asc",
+				Region: "us",
+				Paging: Paging{Page: 2, Limit: 10},
+				Body: Body{
+					Name:   "request",
+					Count:  1,
+					Tags:   []string{"b1", "b2"},
+					Labels: map[string]string{"a": "1", "b": "3"},
+				},
+			},
+		},
+		{
+			name:      "only zero",
+			mergeMode: MergeModeOnlyZero,
+			want: DataType{
+				Tags:   []string{"default"},
+				Sort:   "asc",
+				Region: "us",
+				Paging: Paging{Page: 2, Limit: 10},
+				Body:   newDefaults().Body,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/?tag=t1&tag=t2&sort=asc&page=2",
+				strings.NewReader(`{"name":"request","tags":["b2"],"labels":{"b":"3"}}`))
+			r.Header.Set("Content-Type", "application/json")
+
+			defaults := newDefaults()
+			data := defaults
+			err := Decode(r, &data, WithDefaultRequired(false), WithMergeMode(tt.mergeMode))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, data)
+
+			if tt.mergeMode != MergeModeDefault {
+				// pre-filled values must not be changed in place.
+				require.Equal(t, newDefaults(), defaults)
+			}
+		})
+	}
+}
+
+func TestDecodeMergeModeOptional(t *testing.T) {
+	type DataType struct {
+		Page Optional[int] `inreq:"query"`
+		Sort Optional[int] `inreq:"query"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?page=2&sort=1", nil)
+
+	data := DataType{Page: NewOptional(1)}
+	require.NoError(t, Decode(r, &data, WithMergeMode(MergeModeOnlyZero)))
+	require.Equal(t, NewOptional(1), data.Page)
+	require.Equal(t, NewOptional(1), data.Sort)
+}
+
+func TestDecodeMergeModeBodyFields(t *testing.T) {
+	type Address struct {
+		Street string `json:"street"`
+		Number int    `json:"number"`
+	}
+
+	type Body struct {
+		Name    string            `json:"name"`
+		Count   int               `json:"count"`
+		Active  bool              `json:"active"`
+		Email   *string           `json:"email"`
+		Tags    []string          `json:"tags"`
+		Labels  map[string]string `json:"labels"`
+		Address Address           `json:"address"`
+	}
+
+	type DataType struct {
+		Body Body `inreq:"body"`
+	}
+
+	email := "john@example.com"
+	data := DataType{
+		Body: Body{
+			Name:    "John",
+			Count:   5,
+			Active:  true,
+			Email:   &email,
+			Tags:    []string{"a"},
+			Labels:  map[string]string{"a": "1"},
+			Address: Address{Street: "Main", Number: 10},
+		},
+	}
+
+	r := httptest.NewRequest(http.MethodPatch, "/",
+		strings.NewReader(`{"count":0,"active":false,"email":null,"tags":null,"address":{"number":0}}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	require.NoError(t, Decode(r, &data, WithMergeMode(MergeModeMerge)))
+	require.Equal(t, DataType{
+		Body: Body{
+			Name:    "John",
+			Labels:  map[string]string{"a": "1"},
+			Address: Address{Street: "Main"},
+		},
+	}, data)
+}
+
+func TestDecodeMergeModeBodyFieldsCaseInsensitive(t *testing.T) {
+	type Body struct {
+		Name    string
+		Address struct {
+			Number int
+		}
+		Title string `json:"Title"`
+	}
+
+	type DataType struct {
+		Body Body `inreq:"body"`
+	}
+
+	data := DataType{}
+	data.Body.Name = "John"
+	data.Body.Address.Number = 10
+	data.Body.Title = "Mr"
+
+	r := httptest.NewRequest(http.MethodPatch, "/",
+		strings.NewReader(`{"name":"","ADDRESS":{"number":0},"title":""}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	require.NoError(t, Decode(r, &data, WithMergeMode(MergeModeMerge)))
+	require.Equal(t, DataType{}, data)
+}
+
+func TestDecodeMergeModeFieldPath(t *testing.T) {
+	type DataType struct {
+		Path string `inreq:"fieldpath"`
+	}
+
+	for _, mergeMode := range []MergeMode{MergeModeDefault, MergeModeMerge} {
+		data := DataType{}
+		require.NoError(t, Decode(httptest.NewRequest(http.MethodGet, "/", nil), &data, WithMergeMode(mergeMode),
+			WithDecodeOperation("fieldpath", &testDecodeOperationFieldPath{})))
+		require.Equal(t, "Path", data.Path)
+	}
+}
+
+type testDecodeOperationFieldPath struct {
+}
+
+func (d *testDecodeOperationFieldPath) Decode(ctx DecodeContext, r *http.Request, isList bool, field reflect.Value,
+	tag *Tag) (bool, any, error) {
+	return true, ctx.FieldPath(field), nil
+}
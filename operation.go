@@ -13,11 +13,16 @@ const (
 
 // Default operations.
 const (
-	OperationQuery  string = "query"
-	OperationPath          = "path"
-	OperationHeader        = "header"
-	OperationForm          = "form"
-	OperationBody          = "body"
+	OperationQuery    string = "query"
+	OperationPath            = "path"
+	OperationHeader          = "header"
+	OperationForm            = "form"
+	OperationBody            = "body"
+	OperationCookie          = "cookie"
+	OperationAuth            = "auth"
+	OperationClientIP        = "clientip"
+	OperationRequest         = "request"
+	OperationMulti           = "multi"
 )
 
 // DecodeOperation is the interface for the http request-to-struct decoders.
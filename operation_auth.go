@@ -0,0 +1,183 @@
+package inreq
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Authentication schemes with special handling in the "auth" operation.
+const (
+	AuthSchemeBasic  = "basic"
+	AuthSchemeBearer = "bearer"
+)
+
+// BasicCredentials are the credentials of the "Basic" authentication scheme (RFC 7617).
+type BasicCredentials struct {
+	User     string
+	Password string
+}
+
+var basicCredentialsType = reflect.TypeOf(BasicCredentials{})
+
+// DecodeOperationAuth is a DecodeOperation that gets credentials from the "Authorization" header, or from the
+// header set in the "header" tag option, for the authentication scheme set in the "scheme" tag option.
+// Depending on the field type, it receives the credentials as a string (like the "Bearer" token), as
+// BasicCredentials for the "Basic" scheme, or as a map[string]string of auth-params (like for "Digest").
+// Credentials using other schemes are considered not set. Missing or invalid credentials return an
+// UnauthorizedError.
+type DecodeOperationAuth struct {
+}
+
+func (d *DecodeOperationAuth) Decode(ctx DecodeContext, r *http.Request, isList bool, field reflect.Value,
+	tag *Tag) (bool, any, error) {
+	scheme, ok := tag.Options.Get("scheme")
+	if !ok {
+		return false, nil, fmt.Errorf("%w: the 'scheme' tag option is required for the auth operation",
+			ErrInvalidConfiguration)
+	}
+
+	authScheme, credentials, _ := strings.Cut(r.Header.Get(tag.Options.Value("header", "Authorization")), " ")
+	if !strings.EqualFold(authScheme, scheme) {
+		return false, nil, nil
+	}
+
+	value, err := decodeAuthCredentials(reflectTypeElem(field.Type()), scheme, strings.Trim(credentials, " "))
+	if err != nil {
+		if errors.Is(err, ErrInvalidConfiguration) {
+			return false, nil, err
+		}
+		return false, nil, newUnauthorizedError(tag, err)
+	}
+	return true, value, nil
+}
+
+// IsSensitive returns true, as the values are credentials.
+func (d *DecodeOperationAuth) IsSensitive(tag *Tag) bool {
+	return true
+}
+
+// WrapRequiredError returns an UnauthorizedError for missing credentials.
+func (d *DecodeOperationAuth) WrapRequiredError(tag *Tag, err RequiredError) error {
+	return newUnauthorizedError(tag, err)
+}
+
+func decodeAuthCredentials(typ reflect.Type, scheme string, credentials string) (any, error) {
+	if credentials == "" {
+		return nil, errors.New("missing credentials")
+	}
+
+	switch {
+	case typ == basicCredentialsType:
+		if !strings.EqualFold(scheme, AuthSchemeBasic) {
+			return nil, fmt.Errorf("%w: BasicCredentials requires the basic scheme", ErrInvalidConfiguration)
+		}
+		return parseBasicCredentials(credentials)
+	case typ.Kind() == reflect.Map && typ.Key().Kind() == reflect.String && typ.Elem().Kind() == reflect.String:
+		return parseAuthParams(credentials)
+	case strings.EqualFold(scheme, AuthSchemeBearer) && !isToken68(credentials):
+		return nil, errors.New("invalid bearer token")
+	}
+	return credentials, nil
+}
+
+// parseBasicCredentials decodes the base64 "user:password" credentials. Errors never contain the credentials.
+func parseBasicCredentials(credentials string) (BasicCredentials, error) {
+	b, err := base64.StdEncoding.DecodeString(credentials)
+	if err != nil {
+		return BasicCredentials{}, errors.New("invalid basic credentials encoding")
+	}
+	user, password, ok := strings.Cut(string(b), ":")
+	if !ok {
+		return BasicCredentials{}, errors.New("invalid basic credentials")
+	}
+	return BasicCredentials{User: user, Password: password}, nil
+}
+
+// parseAuthParams parses a comma-separated list of auth-params, like `realm="x", nonce="y"`.
+// Parameter names are lowercased.
+func parseAuthParams(credentials string) (map[string]string, error) {
+	ret := map[string]string{}
+	for _, item := range splitHeaderQuoted(credentials, ',') {
+		item = strings.Trim(item, " \t")
+		if item == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(item, "=")
+		name, value = strings.Trim(name, " \t"), strings.Trim(value, " \t")
+		if !ok || name == "" {
+			return nil, errors.New("invalid auth parameter")
+		}
+		if s, ok := unquoteHeaderString(value); ok {
+			value = s
+		}
+		ret[strings.ToLower(name)] = value
+	}
+	return ret, nil
+}
+
+// isToken68 returns whether the value is a valid RFC 9110 token68, used by the "Bearer" scheme.
+func isToken68(value string) bool {
+	value = strings.TrimRight(value, "=")
+	if value == "" {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		if c := value[i]; !isAlpha(c) && !isDigit(c) && strings.IndexByte("-._~+/", c) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// An UnauthorizedError is returned by the "auth" operation when the credentials are missing or invalid.
+// It never contains the credentials.
+type UnauthorizedError struct {
+	Scheme string // authentication scheme, from the "scheme" tag option.
+	Realm  string // realm, from the "realm" tag option.
+	Err    error  // RequiredError if the credentials are missing, or the reason they are invalid.
+}
+
+func newUnauthorizedError(tag *Tag, err error) UnauthorizedError {
+	return UnauthorizedError{
+		Scheme: tag.Options.Value("scheme", ""),
+		Realm:  tag.Options.Value("realm", ""),
+		Err:    err,
+	}
+}
+
+func (e UnauthorizedError) Error() string {
+	return fmt.Sprintf("unauthorized for scheme '%s': %s", e.Scheme, e.Err)
+}
+
+func (e UnauthorizedError) Unwrap() error {
+	return e.Err
+}
+
+// Challenge returns the "WWW-Authenticate" challenge for the error, like `Bearer realm="api"`.
+// For the "Bearer" scheme, invalid tokens add the RFC 6750 `error="invalid_token"` parameter.
+func (e UnauthorizedError) Challenge() string {
+	scheme := e.Scheme
+	switch {
+	case strings.EqualFold(scheme, AuthSchemeBasic):
+		scheme = "Basic"
+	case strings.EqualFold(scheme, AuthSchemeBearer):
+		scheme = "Bearer"
+	}
+
+	var params []string
+	if e.Realm != "" {
+		params = append(params, "realm="+quoteHeaderString(e.Realm))
+	}
+	var requiredErr RequiredError
+	if scheme == "Bearer" && e.Err != nil && !errors.As(e.Err, &requiredErr) {
+		params = append(params, `error="invalid_token"`)
+	}
+	if len(params) == 0 {
+		return scheme
+	}
+	return scheme + " " + strings.Join(params, ", ")
+}
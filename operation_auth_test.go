@@ -0,0 +1,203 @@
+package inreq
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeAuth(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		data   func() any
+		want   any
+	}{
+		{
+			name:  "bearer token",
+			value: "Bearer abc.def-ghi==",
+			data: func() any {
+				return &struct {
+					Token string `inreq:"auth,scheme=bearer"`
+				}{}
+			},
+			want: &struct {
+				Token string `inreq:"auth,scheme=bearer"`
+			}{Token: "abc.def-ghi=="},
+		},
+		{
+			name:  "basic credentials",
+			value: "basic " + base64.StdEncoding.EncodeToString([]byte("user:pass:word")),
+			data: func() any {
+				return &struct {
+					Credentials *BasicCredentials `inreq:"auth,scheme=basic"`
+				}{}
+			},
+			want: &struct {
+				Credentials *BasicCredentials `inreq:"auth,scheme=basic"`
+			}{Credentials: &BasicCredentials{User: "user", Password: "pass:word"}},
+		},
+		{
+			name:  "auth params",
+			value: `Digest username="Mufasa", realm="a, b",nonce=xyz`,
+			data: func() any {
+				return &struct {
+					Params map[string]string `inreq:"auth,scheme=digest"`
+				}{}
+			},
+			want: &struct {
+				Params map[string]string `inreq:"auth,scheme=digest"`
+			}{Params: map[string]string{"username": "Mufasa", "realm": "a, b", "nonce": "xyz"}},
+		},
+		{
+			name:   "custom header",
+			header: "Proxy-Authorization",
+			value:  "ApiKey k1",
+			data: func() any {
+				return &struct {
+					Key string `inreq:"auth,scheme=ApiKey,header=Proxy-Authorization"`
+				}{}
+			},
+			want: &struct {
+				Key string `inreq:"auth,scheme=ApiKey,header=Proxy-Authorization"`
+			}{Key: "k1"},
+		},
+		{
+			name:  "other scheme not required",
+			value: "Basic dXNlcjpwYXNz",
+			data: func() any {
+				return &struct {
+					Token string `inreq:"auth,scheme=bearer,required=false"`
+				}{}
+			},
+			want: &struct {
+				Token string `inreq:"auth,scheme=bearer,required=false"`
+			}{},
+		},
+		{
+			name: "optional not set",
+			data: func() any {
+				return &struct {
+					Token Optional[string] `inreq:"auth,scheme=bearer"`
+				}{}
+			},
+			want: &struct {
+				Token Optional[string] `inreq:"auth,scheme=bearer"`
+			}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.value != "" {
+				header := tt.header
+				if header == "" {
+					header = "Authorization"
+				}
+				r.Header.Set(header, tt.value)
+			}
+
+			data := tt.data()
+			require.NoError(t, Decode(r, data))
+			require.Equal(t, tt.want, data)
+		})
+	}
+}
+
+func TestDecodeAuthError(t *testing.T) {
+	tests := []struct {
+		name          string
+		value         string
+		data          any
+		wantChallenge string
+		wantRequired  bool
+	}{
+		{
+			name: "missing",
+			data: &struct {
+				Token string `inreq:"auth,scheme=bearer,realm=api"`
+			}{},
+			wantChallenge: `Bearer realm="api"`,
+			wantRequired:  true,
+		},
+		{
+			name:  "other scheme",
+			value: "Basic dXNlcjpwYXNz",
+			data: &struct {
+				Token string `inreq:"auth,scheme=bearer"`
+			}{},
+			wantChallenge: `Bearer`,
+			wantRequired:  true,
+		},
+		{
+			name:  "invalid token",
+			value: "Bearer a b",
+			data: &struct {
+				Token string `inreq:"auth,scheme=bearer,realm=api"`
+			}{},
+			wantChallenge: `Bearer realm="api", error="invalid_token"`,
+		},
+		{
+			name:  "invalid basic credentials",
+			value: "Basic " + base64.StdEncoding.EncodeToString([]byte("secret")),
+			data: &struct {
+				C BasicCredentials `inreq:"auth,scheme=basic,realm=x\"y"`
+			}{},
+			wantChallenge: `Basic realm="x\"y"`,
+		},
+		{
+			name:  "empty credentials",
+			value: "Basic",
+			data: &struct {
+				C BasicCredentials `inreq:"auth,scheme=basic"`
+			}{},
+			wantChallenge: `Basic`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.value != "" {
+				r.Header.Set("Authorization", tt.value)
+			}
+
+			err := Decode(r, tt.data)
+			var uerr UnauthorizedError
+			require.ErrorAs(t, err, &uerr)
+			require.Equal(t, tt.wantChallenge, uerr.Challenge())
+			var requiredErr RequiredError
+			require.Equal(t, tt.wantRequired, errors.As(err, &requiredErr))
+			require.NotContains(t, err.Error(), "secret")
+			require.NotContains(t, err.Error(), "dXNlcjpwYXNz")
+
+			var ferr FieldError
+			require.ErrorAs(t, err, &ferr)
+			require.Nil(t, ferr.Value)
+		})
+	}
+}
+
+func TestDecodeAuthConfigurationError(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer abc")
+
+	err := Decode(r, &struct {
+		Token string `inreq:"auth"`
+	}{})
+	require.ErrorIs(t, err, ErrInvalidConfiguration)
+	require.False(t, errors.As(err, &UnauthorizedError{}))
+
+	err = Decode(r, &struct {
+		C BasicCredentials `inreq:"auth,scheme=bearer"`
+	}{})
+	require.ErrorIs(t, err, ErrInvalidConfiguration)
+	require.False(t, strings.Contains(err.Error(), "abc"))
+}
@@ -1,6 +1,7 @@
 package inreq
 
 import (
+	"bytes"
 	"encoding"
 	"encoding/json"
 	"encoding/xml"
@@ -75,6 +76,21 @@ func decodeBodyRaw(ctx DecodeContext, r *http.Request, field reflect.Value) (boo
 }
 
 func decodeBody(ctx DecodeContext, r *http.Request, field reflect.Value, tag *Tag) (bool, any, error) {
+	// check for patch documents
+	if field.CanAddr() {
+		if patch, ok := field.Addr().Interface().(patchBody); ok {
+			return decodeBodyPatch(ctx, r, patch, tag)
+		}
+	}
+	if field.Kind() == reflect.Pointer && field.Type().Implements(patchBodyType) {
+		patch := reflect.New(field.Type().Elem())
+		found, data, err := decodeBodyPatch(ctx, r, patch.Interface().(patchBody), tag)
+		if found && err == nil {
+			field.Set(patch)
+		}
+		return found, data, err
+	}
+
 	// check for raw data
 	rfound, found, data, err := decodeBodyRaw(ctx, r, field)
 	if rfound {
@@ -90,6 +106,12 @@ func decodeBody(ctx DecodeContext, r *http.Request, field reflect.Value, tag *Ta
 	found, data, err = ctx.BodyDecoder().Unmarshal(ctx, tag.Options.Value("type", ""),
 		r, fv.Interface())
 	if found {
+		if err == nil && data == IgnoreDecodeValue {
+			if err = checkBodyEnums(field); err != nil {
+				return true, nil, err
+			}
+			setBodyFields(ctx, field)
+		}
 		return found, data, err
 	}
 
@@ -113,9 +135,39 @@ func decodeBody(ctx DecodeContext, r *http.Request, field reflect.Value, tag *Ta
 		}
 	}
 
+	// a body with an explicit content type which no decoder supports. This is only returned for required fields.
+	if contentType := r.Header.Get("Content-Type"); contentType != "" && r.Body != http.NoBody &&
+		tag.Options.Value("type", "") == "" {
+		return false, nil, UnsupportedMediaTypeError{MediaType: contentType}
+	}
+
 	return false, nil, nil
 }
 
+// decodeBodyPatch decodes a patch document, which must be sent with the patch media type unless the "type" option
+// is set.
+func decodeBodyPatch(ctx DecodeContext, r *http.Request, patch patchBody, tag *Tag) (bool, any, error) {
+	mediaType := patch.patchMediaType()
+	if tag.Options.Value("type", "") == "" {
+		contentType := r.Header.Get("Content-Type")
+		if ct, _, err := mime.ParseMediaType(contentType); err != nil || ct != mediaType {
+			if contentType == "" && r.Body == http.NoBody {
+				return false, nil, nil
+			}
+			return false, nil, UnsupportedMediaTypeError{MediaType: contentType, Err: err}
+		}
+	}
+
+	found, data, err := decodeBodyReadData(ctx, r)
+	if err != nil || !found {
+		return found, nil, err
+	}
+	if err = patch.decodePatch(data); err != nil {
+		return true, nil, BodyDecodeError{MediaType: mediaType, Err: err}
+	}
+	return true, IgnoreDecodeValue, nil
+}
+
 // defaultBodyDecoder decodes JSON and XML to structs.
 type defaultBodyDecoder struct {
 }
@@ -140,9 +192,9 @@ func (d defaultBodyDecoder) Unmarshal(ctx DecodeContext, typeParam string, r *ht
 		var err error
 		contentType := r.Header.Get("Content-Type")
 		if contentType != "" {
-			mediaType, _, err = mime.ParseMediaType(r.Header.Get("Content-Type"))
+			mediaType, _, err = mime.ParseMediaType(contentType)
 			if err != nil {
-				return false, nil, fmt.Errorf("error detecting body content type: %w", err)
+				return false, nil, UnsupportedMediaTypeError{MediaType: contentType, Err: err}
 			}
 		}
 	}
@@ -150,16 +202,27 @@ func (d defaultBodyDecoder) Unmarshal(ctx DecodeContext, typeParam string, r *ht
 	switch mediaType {
 	case "application/json":
 		ctx.DecodedBody()
-		err := json.NewDecoder(r.Body).Decode(&data)
+		// keep a copy of the body to find the fields which were present, only if they are needed.
+		var body io.Reader = r.Body
+		recorder, isRecorder := ctx.(jsonBodyRecorder)
+		isRecorder = isRecorder && recorder.recordJSONBody(data)
+		var buf bytes.Buffer
+		if isRecorder {
+			body = io.TeeReader(r.Body, &buf)
+		}
+		err := json.NewDecoder(body).Decode(&data)
 		if err != nil {
-			return true, nil, fmt.Errorf("error parsing JSON body: %w", err)
+			return true, nil, BodyDecodeError{MediaType: mediaType, Err: err}
+		}
+		if isRecorder {
+			recorder.setJSONBody(buf.Bytes())
 		}
 		return true, IgnoreDecodeValue, nil
 	case "text/xml", "application/xml":
 		ctx.DecodedBody()
 		err := xml.NewDecoder(r.Body).Decode(&data)
 		if err != nil {
-			return true, nil, fmt.Errorf("error parsing XML body: %w", err)
+			return true, nil, BodyDecodeError{MediaType: mediaType, Err: err}
 		}
 		return true, IgnoreDecodeValue, nil
 	}
@@ -462,3 +462,29 @@ func TestDecodeBodyXml(t *testing.T) {
 	require.Equal(t, "15", data.Val)
 
 }
+
+func TestDecodeBodyUnsupportedMediaType(t *testing.T) {
+	type Body struct {
+		Name string
+	}
+
+	newRequest := func() *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`name=John`))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		return r
+	}
+
+	err := Decode(newRequest(), &struct {
+		Body Body `inreq:"body"`
+	}{})
+	var merr UnsupportedMediaTypeError
+	require.ErrorAs(t, err, &merr)
+
+	data := &struct {
+		Name string `inreq:"form"`
+		Body *Body  `inreq:"body,required=false"`
+	}{}
+	require.NoError(t, Decode(newRequest(), data))
+	require.Equal(t, "John", data.Name)
+	require.Nil(t, data.Body)
+}
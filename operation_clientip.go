@@ -0,0 +1,168 @@
+package inreq
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"reflect"
+	"strings"
+)
+
+// DefaultClientIPHeaders are the headers checked by the "clientip" operation, in order of precedence.
+var DefaultClientIPHeaders = []string{"Forwarded", "X-Forwarded-For", "X-Real-IP"}
+
+var (
+	netIPType     = reflect.TypeOf(net.IP{})
+	netipAddrType = reflect.TypeOf(netip.Addr{})
+)
+
+// DecodeOperationClientIP is a DecodeOperation that gets the client IP address of the request, into string,
+// net.IP or netip.Addr fields.
+// If the request comes from a proxy in TrustedProxies (see WithTrustedProxies), the first client IP header present
+// (see WithClientIPHeaders) is walked from the right, skipping trusted proxies, and the first untrusted address is
+// the client. Otherwise, the address of r.RemoteAddr is used, as forwarding headers can be set by anyone.
+type DecodeOperationClientIP struct {
+	TrustedProxies []netip.Prefix // proxies trusted to set the forwarding headers.
+	Headers        []string       // client IP headers, in order of precedence. Default is DefaultClientIPHeaders.
+}
+
+func (d *DecodeOperationClientIP) Decode(ctx DecodeContext, r *http.Request, isList bool, field reflect.Value,
+	tag *Tag) (bool, any, error) {
+	headers := d.Headers
+	if headers == nil {
+		headers = DefaultClientIPHeaders
+	}
+	addr, ok := clientIP(r, d.TrustedProxies, headers)
+	if !ok {
+		return false, nil, nil
+	}
+
+	switch typ := reflectTypeElem(field.Type()); typ {
+	case netipAddrType:
+		return true, addr, nil
+	case netIPType:
+		return true, net.IP(addr.AsSlice()), nil
+	default:
+		if typ.Kind() != reflect.String {
+			return false, nil, fmt.Errorf("unsupported client IP field type '%s'", typ)
+		}
+	}
+	return true, addr.String(), nil
+}
+
+// clientIP returns the client address of the request, checking the forwarding headers only when the request comes
+// from a trusted proxy.
+func clientIP(r *http.Request, trustedProxies []netip.Prefix, headers []string) (netip.Addr, bool) {
+	remote, ok := remoteAddr(r)
+	if !ok || !isTrustedProxy(remote, trustedProxies) {
+		return remote, ok
+	}
+
+	for _, header := range headers {
+		values := r.Header.Values(header)
+		if len(values) == 0 {
+			continue
+		}
+		var chain []string
+		if http.CanonicalHeaderKey(header) == "Forwarded" {
+			chain = parseForwardedFor(values)
+		} else {
+			chain = parseHeaderList(values)
+		}
+		if len(chain) == 0 {
+			continue
+		}
+		return walkForwardingChain(remote, chain, trustedProxies), true
+	}
+	return remote, true
+}
+
+// walkForwardingChain walks the chain from the right, returning the first address which is not a trusted proxy.
+// Invalid or obfuscated addresses (like "unknown" in "Forwarded") stop the walk, returning the last trusted hop, as
+// any address on its left could have been set by the client.
+func walkForwardingChain(remote netip.Addr, chain []string, trustedProxies []netip.Prefix) netip.Addr {
+	client := remote
+	for i := len(chain) - 1; i >= 0; i-- {
+		addr, ok := parseForwardedAddr(chain[i])
+		if !ok {
+			break
+		}
+		client = addr
+		if !isTrustedProxy(addr, trustedProxies) {
+			break
+		}
+	}
+	return client
+}
+
+// remoteAddr returns the address of r.RemoteAddr, which may or may not contain a port.
+func remoteAddr(r *http.Request) (netip.Addr, bool) {
+	return parseForwardedAddr(r.RemoteAddr)
+}
+
+func isTrustedProxy(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseForwardedFor returns the "for" parameter of each element of the RFC 7239 "Forwarded" header. Elements
+// without it are returned as blank, so they stop the walk of the chain.
+func parseForwardedFor(values []string) []string {
+	var ret []string
+	for _, element := range parseForwarded(values) {
+		ret = append(ret, element["for"])
+	}
+	return ret
+}
+
+// parseForwarded parses the elements of the RFC 7239 "Forwarded" header, like `for=192.0.2.60;proto=http`, into
+// maps of lowercased parameter names to unquoted values.
+func parseForwarded(values []string) []map[string]string {
+	var ret []map[string]string
+	for _, value := range values {
+		for _, element := range splitHeaderQuoted(value, ',') {
+			if strings.Trim(element, " \t") == "" {
+				continue
+			}
+			params := map[string]string{}
+			for _, pair := range splitHeaderQuoted(element, ';') {
+				name, pvalue, _ := strings.Cut(pair, "=")
+				name, pvalue = strings.ToLower(strings.Trim(name, " \t")), strings.Trim(pvalue, " \t")
+				if s, ok := unquoteHeaderString(pvalue); ok {
+					pvalue = s
+				}
+				if name != "" {
+					params[name] = pvalue
+				}
+			}
+			ret = append(ret, params)
+		}
+	}
+	return ret
+}
+
+// parseForwardedAddr parses an address which may contain a port, like "192.0.2.43:47011" or "[2001:db8::1]:4711".
+// IPv4-mapped IPv6 addresses are unmapped.
+func parseForwardedAddr(value string) (netip.Addr, bool) {
+	value = strings.Trim(value, " \t")
+	addr, err := netip.ParseAddr(value)
+	if err != nil {
+		addrPort, perr := netip.ParseAddrPort(value)
+		if perr != nil {
+			if !strings.HasPrefix(value, "[") || !strings.HasSuffix(value, "]") {
+				return netip.Addr{}, false
+			}
+			if addr, err = netip.ParseAddr(value[1 : len(value)-1]); err != nil {
+				return netip.Addr{}, false
+			}
+		} else {
+			addr = addrPort.Addr()
+		}
+	}
+	return addr.Unmap(), true
+}
@@ -0,0 +1,154 @@
+package inreq
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("2001:db8:ffff::/48")}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string][]string
+		options    []DefaultOption
+		want       string
+	}{
+		{
+			name:       "remote address",
+			remoteAddr: "192.0.2.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.1"}},
+			want:       "192.0.2.1",
+		},
+		{
+			name:       "untrusted proxy",
+			remoteAddr: "192.0.2.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.1"}},
+			options:    []DefaultOption{WithTrustedProxies(trusted)},
+			want:       "192.0.2.1",
+		},
+		{
+			name:       "forwarded for chain",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"203.0.113.9, 198.51.100.1", "10.0.0.2"}},
+			options:    []DefaultOption{WithTrustedProxies(trusted)},
+			want:       "198.51.100.1",
+		},
+		{
+			name:       "all trusted",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}},
+			options:    []DefaultOption{WithTrustedProxies(trusted)},
+			want:       "10.0.0.3",
+		},
+		{
+			name:       "invalid address stops walk",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"X-Forwarded-For": {"198.51.100.1, garbage, 10.0.0.2"}},
+			options:    []DefaultOption{WithTrustedProxies(trusted)},
+			want:       "10.0.0.2",
+		},
+		{
+			name:       "forwarded precedence",
+			remoteAddr: "[2001:db8:ffff::1]:1234",
+			headers: map[string][]string{
+				"Forwarded":       {`for=192.0.2.60;proto=http, For="[2001:db8:cafe::17]:4711";by=10.0.0.1`},
+				"X-Forwarded-For": {"198.51.100.1"},
+			},
+			options: []DefaultOption{WithTrustedProxies(trusted)},
+			want:    "2001:db8:cafe::17",
+		},
+		{
+			name:       "forwarded unknown",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string][]string{"Forwarded": {"for=192.0.2.60, for=unknown"}},
+			options:    []DefaultOption{WithTrustedProxies(trusted)},
+			want:       "10.0.0.1",
+		},
+		{
+			name:       "custom headers",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				"X-Forwarded-For": {"198.51.100.1"},
+				"X-Real-IP":       {"192.0.2.60"},
+			},
+			options: []DefaultOption{WithTrustedProxies(trusted), WithClientIPHeaders("X-Real-IP")},
+			want:    "192.0.2.60",
+		},
+		{
+			name:       "operation fields",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				"X-Forwarded-For": {"198.51.100.1"},
+				"X-Real-IP":       {"192.0.2.60"},
+			},
+			options: []DefaultOption{WithDecodeOperation(OperationClientIP, &DecodeOperationClientIP{
+				TrustedProxies: trusted,
+				Headers:        []string{"X-Real-IP"},
+			})},
+			want: "192.0.2.60",
+		},
+		{
+			name:       "mapped address",
+			remoteAddr: "[::ffff:192.0.2.1]:1234",
+			want:       "192.0.2.1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for name, values := range tt.headers {
+				for _, value := range values {
+					r.Header.Add(name, value)
+				}
+			}
+
+			data := &struct {
+				IP string `inreq:"clientip"`
+			}{}
+			require.NoError(t, NewDecoder(tt.options...).Decode(r, data))
+			require.Equal(t, tt.want, data.IP)
+		})
+	}
+}
+
+func TestDecodeClientIPTypes(t *testing.T) {
+	type DataType struct {
+		Addr    netip.Addr  `inreq:"clientip"`
+		IP      net.IP      `inreq:"clientip"`
+		AddrPtr *netip.Addr `inreq:"clientip"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "192.0.2.1:1234"
+
+	data, err := DecodeType[DataType](r)
+	require.NoError(t, err)
+	require.Equal(t, netip.MustParseAddr("192.0.2.1"), data.Addr)
+	require.Equal(t, net.IP{192, 0, 2, 1}, data.IP)
+	require.Equal(t, netip.MustParseAddr("192.0.2.1"), *data.AddrPtr)
+}
+
+func TestDecodeClientIPError(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "pipe"
+
+	err := Decode(r, &struct {
+		IP string `inreq:"clientip"`
+	}{})
+	require.ErrorAs(t, err, &RequiredError{})
+
+	r.RemoteAddr = "192.0.2.1:1234"
+	err = Decode(r, &struct {
+		IP int `inreq:"clientip"`
+	}{})
+	require.Error(t, err)
+}
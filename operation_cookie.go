@@ -0,0 +1,29 @@
+package inreq
+
+import (
+	"net/http"
+	"reflect"
+)
+
+// DecodeOperationCookie is a DecodeOperation that gets values from HTTP cookies.
+type DecodeOperationCookie struct {
+}
+
+func (d *DecodeOperationCookie) Decode(ctx DecodeContext, r *http.Request, isList bool, field reflect.Value,
+	tag *Tag) (bool, any, error) {
+	var values []string
+	for _, cookie := range r.Cookies() {
+		if cookie.Name == tag.Name {
+			values = append(values, cookie.Value)
+		}
+	}
+
+	if len(values) == 0 {
+		return false, nil, nil
+	}
+
+	if isList {
+		return true, values, nil
+	}
+	return true, values[0], nil
+}
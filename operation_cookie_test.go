@@ -0,0 +1,87 @@
+package inreq
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeCookie(t *testing.T) {
+	tests := []struct {
+		name    string
+		cookies [][]string
+		data    interface{}
+		want    interface{}
+		options []AnyOption
+		wantErr bool
+	}{
+		{
+			name:    "decode cookie",
+			cookies: [][]string{{"val", "x1"}},
+			data: &struct {
+				Val string `inreq:"cookie"`
+			}{},
+			want: &struct {
+				Val string `inreq:"cookie"`
+			}{
+				Val: "x1",
+			},
+		},
+		{
+			name:    "decode cookie with slice",
+			cookies: [][]string{{"val", "5"}, {"val", "6"}},
+			data: &struct {
+				Val []int32 `inreq:"cookie"`
+			}{},
+			want: &struct {
+				Val []int32 `inreq:"cookie"`
+			}{
+				Val: []int32{5, 6},
+			},
+		},
+		{
+			name:    "decode cookie with name",
+			cookies: [][]string{{"session_id", "x1"}},
+			data: &struct {
+				Val string `inreq:"cookie,name=session_id"`
+			}{},
+			want: &struct {
+				Val string `inreq:"cookie,name=session_id"`
+			}{
+				Val: "x1",
+			},
+		},
+		{
+			name:    "decode cookie with name error",
+			cookies: [][]string{{"val", "x1"}},
+			data: &struct {
+				Val string `inreq:"cookie,name=session_id"`
+			}{},
+			wantErr: true,
+		},
+	}
+
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/", nil)
+			for _, cookie := range tt.cookies {
+				r.AddCookie(&http.Cookie{Name: cookie[0], Value: cookie[1]})
+			}
+
+			options := append(append([]AnyOption{}, tt.options...),
+				WithDecodeOperation(OperationCookie, &DecodeOperationCookie{}),
+			)
+
+			err := CustomDecode(r, tt.data, options...)
+			if !tt.wantErr {
+				require.NoError(t, err)
+				require.Equal(t, tt.want, tt.data)
+			} else if err == nil {
+				t.Errorf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
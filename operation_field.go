@@ -0,0 +1,305 @@
+package inreq
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+
+	"github.com/rrgmc/instruct"
+)
+
+// fieldDecodeOperation wraps a DecodeOperation, resolving the returned value into the struct field itself instead
+// of leaving it to the instruct decoder, so that steps which need the final field value (like validation) can be
+// executed right after each field is resolved.
+type fieldDecodeOperation struct {
+	name             string
+	operation        DecodeOperation
+	resolver         Resolver
+	optionalRequired *instruct.Decoder[*http.Request, DecodeContext] // set if DefaultRequired is true.
+}
+
+// wrapDecodeOperations wraps all decode operations with fieldDecodeOperation.
+func wrapDecodeOperations(o *instruct.DefaultOptions[*http.Request, DecodeContext]) {
+	unwrapped := map[string]DecodeOperation{}
+	for name, operation := range o.DecodeOperations {
+		if fo, ok := operation.(*fieldDecodeOperation); ok {
+			operation = fo.operation
+		}
+		unwrapped[name] = operation
+	}
+
+	var optionalRequired *instruct.Decoder[*http.Request, DecodeContext]
+	if o.DefaultRequired {
+		optionalRequired = newOptionalRequiredDecoder(*o)
+	}
+
+	operations := map[string]DecodeOperation{}
+	for name, operation := range unwrapped {
+		if _, ok := operation.(*DecodeOperationMulti); ok {
+			// bind the multi-source operation to the source operations.
+			operation = &DecodeOperationMulti{
+				operations: unwrapped,
+			}
+		}
+		operations[name] = &fieldDecodeOperation{
+			name:             name,
+			operation:        operation,
+			resolver:         o.Resolver,
+			optionalRequired: optionalRequired,
+		}
+	}
+	o.DecodeOperations = operations
+}
+
+func (d *fieldDecodeOperation) Decode(ctx DecodeContext, r *http.Request, isList bool, field reflect.Value,
+	tag *Tag) (bool, any, error) {
+	if tag.IsSO {
+		// struct options are set by the instruct decoder.
+		found, value, err := d.operation.Decode(ctx, r, isList, field, tag)
+		if err != nil {
+			return false, nil, d.fieldError(ctx, field, tag, nil, err)
+		}
+		return found, value, nil
+	}
+
+	found, value, stag, err := d.decodeField(ctx, r, isList, field, tag)
+	if err != nil && !found && !d.isRequired(ctx, field, tag) {
+		// a body with an unsupported media type is only an error for required fields, so optional body fields are
+		// not set for requests sent with other media types, like forms.
+		var mediaTypeErr UnsupportedMediaTypeError
+		if errors.As(err, &mediaTypeErr) {
+			err = nil
+		}
+	}
+	if err == nil && !found && d.isRequired(ctx, field, tag) {
+		rerr := RequiredError{
+			Operation: tag.Operation,
+			FieldName: ctx.FieldPath(field),
+			TagName:   tag.Name,
+		}
+		err = rerr
+		if w, ok := d.operation.(RequiredErrorWrapper); ok {
+			err = w.WrapRequiredError(tag, rerr)
+		}
+	}
+	if err != nil {
+		ferr := d.fieldError(ctx, field, stag, value, err)
+		if !ctx.CollectErrors() || errors.Is(err, ErrInvalidConfiguration) {
+			return false, nil, ferr
+		}
+		// record the error and signal the field as set, so decoding can continue.
+		d.collectError(ctx, ferr)
+		return true, IgnoreDecodeValue, nil
+	}
+	if !found {
+		if _, ok := asOptional(field); ok {
+			// signal the field as set, as the instruct decoder would check the "required" flag again.
+			return true, IgnoreDecodeValue, nil
+		}
+		return false, nil, nil
+	}
+	return true, IgnoreDecodeValue, nil
+}
+
+// decodeField calls the wrapped operation, resolving and validating the returned value.
+// The raw value returned by the operation is returned to be used in errors, along with the tag of the source which
+// returned it, which is only different from the field tag for multi-source operations.
+func (d *fieldDecodeOperation) decodeField(ctx DecodeContext, r *http.Request, isList bool, field reflect.Value,
+	tag *Tag) (bool, any, *Tag, error) {
+	// Optional fields are decoded into their inner value.
+	target := field
+	optional, isOptional := asOptional(field)
+	if isOptional {
+		target = optional.optionalValue()
+		isList = isListField(target)
+	}
+
+	// when using a merge mode, decode into a new value to be merged into the field.
+	mergeMode := ctx.MergeMode()
+	dest, isZero := target, field.IsZero()
+	if mergeMode != MergeModeDefault {
+		target = reflect.New(dest.Type()).Elem()
+		if s, ok := ctx.(fieldTargetSetter); ok {
+			s.setFieldTarget(target, field)
+			defer s.setFieldTarget(reflect.Value{}, reflect.Value{})
+		}
+	}
+
+	var found bool
+	var value any
+	var err error
+	stag := tag
+	if mo, ok := d.operation.(*DecodeOperationMulti); ok {
+		found, value, stag, err = mo.decodeSource(ctx, r, isList, target, tag)
+	} else {
+		found, value, err = d.operation.Decode(ctx, r, isList, target, tag)
+	}
+	if err != nil || !found {
+		return false, nil, stag, err
+	}
+
+	isNull := isOptional && isNullValue(stag, value)
+	if value == IgnoreDecodeValue {
+		value = nil
+	} else if !isNull {
+		if err = d.resolve(target, stag, value); err != nil {
+			return false, value, stag, err
+		}
+	}
+	if mergeMode != MergeModeOnlyZero || isZero {
+		if mergeMode != MergeModeDefault {
+			var fields BodyFields
+			if stag.Operation == OperationBody {
+				fields = ctx.BodyFields()
+			}
+			mergeFieldValue(dest, target, mergeMode, fields)
+		}
+		if isOptional {
+			optional.setOptional(isNull)
+		}
+	}
+
+	if err = validateField(ctx, field, stag); err != nil {
+		return false, value, stag, err
+	}
+
+	if stag != tag {
+		traceSource(ctx, FieldSource{
+			FieldName: ctx.FieldPath(field),
+			Operation: stag.Operation,
+			TagName:   stag.Name,
+		})
+	}
+
+	return true, value, stag, nil
+}
+
+// resolve converts the value using the tag options and resolves it into the field.
+func (d *fieldDecodeOperation) resolve(field reflect.Value, tag *Tag, value any) error {
+	value, err := convertFieldValue(tag, field.Type(), value)
+	if err != nil {
+		return err
+	}
+	if v := reflect.ValueOf(value); v.IsValid() && field.Kind() == reflect.Pointer && v.Type() == field.Type() {
+		// converted pointers, like *time.Location, are set as is, as the Resolver would copy the value.
+		field.Set(v)
+		return nil
+	}
+	return d.resolver.Resolve(field, value)
+}
+
+// isRequired returns whether the field is required. Optional fields are only required if the "required" tag
+// option is set explicitly, which is only known from the parsed tag if DefaultRequired is false.
+func (d *fieldDecodeOperation) isRequired(ctx DecodeContext, field reflect.Value, tag *Tag) bool {
+	if _, ok := asOptional(field); !ok || !tag.Required || d.optionalRequired == nil {
+		return tag.Required
+	}
+	f, ok := ctx.(optionalRequiredFinder)
+	return ok && f.isOptionalRequired(d.optionalRequired, field)
+}
+
+// fieldError wraps the error in a FieldError, redacting the value if needed.
+func (d *fieldDecodeOperation) fieldError(ctx DecodeContext, field reflect.Value, tag *Tag, value any,
+	err error) FieldError {
+	ret := FieldError{
+		Operation: tag.Operation,
+		FieldName: ctx.FieldPath(field),
+		TagName:   tag.Name,
+		Value:     value,
+		Err:       err,
+	}
+	if ctx.IsSensitive(tag) {
+		ret.Err = redactError(err)
+		if value != nil {
+			ret.Value = RedactedValue
+			ret.Redacted = true
+		}
+	}
+	return localizeFieldError(ctx, ret)
+}
+
+func (d *fieldDecodeOperation) IsSensitive(tag *Tag) bool {
+	s, ok := d.operation.(SensitiveOperation)
+	return ok && s.IsSensitive(tag)
+}
+
+func (d *fieldDecodeOperation) Validate(ctx DecodeContext, r *http.Request) error {
+	v, ok := d.operation.(instruct.DecodeOperationValidate[*http.Request, DecodeContext])
+	if !ok {
+		return nil
+	}
+	err := v.Validate(ctx, r)
+	if err == nil {
+		return nil
+	}
+	ferr := localizeFieldError(ctx, FieldError{
+		Operation: d.name,
+		Err:       err,
+	})
+	if ctx.CollectErrors() {
+		d.collectError(ctx, ferr)
+		return nil
+	}
+	return ferr
+}
+
+// RequiredErrorWrapper can be implemented by a DecodeOperation to return a custom error for required fields which
+// are not set, like the UnauthorizedError of the "auth" operation.
+type RequiredErrorWrapper interface {
+	WrapRequiredError(tag *Tag, err RequiredError) error
+}
+
+// SensitiveOperation can be implemented by a DecodeOperation whose values must be redacted from errors even if the
+// ValueRedactor doesn't consider them sensitive, like credentials. The "sensitive" tag option has precedence over it.
+type SensitiveOperation interface {
+	IsSensitive(tag *Tag) bool
+}
+
+// decodeErrorCollector is implemented by the decode context to collect errors when CollectErrors is true.
+type decodeErrorCollector interface {
+	addError(err FieldError)
+}
+
+// errorLocalizer is implemented by the decode context to render localized error messages.
+type errorLocalizer interface {
+	localizeError(err FieldError) (string, bool)
+}
+
+// localizeFieldError sets the localized message of the error, if available.
+func localizeFieldError(ctx DecodeContext, err FieldError) FieldError {
+	if l, ok := ctx.(errorLocalizer); ok {
+		if msg, ok := l.localizeError(err); ok {
+			err.Message = msg
+		}
+	}
+	return err
+}
+
+// optionalRequiredFinder is implemented by the decode context to return whether the "required" tag option is set
+// on an Optional field.
+type optionalRequiredFinder interface {
+	isOptionalRequired(dec *instruct.Decoder[*http.Request, DecodeContext], field reflect.Value) bool
+}
+
+// fieldTargetSetter is implemented by the decode context to return the path of the field for the value which is
+// decoded in its place when using a merge mode.
+type fieldTargetSetter interface {
+	setFieldTarget(target reflect.Value, field reflect.Value)
+}
+
+// sourceTracer is implemented by the decode context to trace the sources of multi-source fields.
+type sourceTracer interface {
+	traceSource(source FieldSource)
+}
+
+func traceSource(ctx DecodeContext, source FieldSource) {
+	if t, ok := ctx.(sourceTracer); ok {
+		t.traceSource(source)
+	}
+}
+
+func (d *fieldDecodeOperation) collectError(ctx DecodeContext, err FieldError) {
+	if c, ok := ctx.(decodeErrorCollector); ok {
+		c.addError(err)
+	}
+}
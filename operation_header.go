@@ -3,9 +3,20 @@ package inreq
 import (
 	"net/http"
 	"reflect"
+	"strings"
 )
 
 // DecodeOperationHeader is a DecodeOperation that gets values from HTTP headers.
+// Values are parsed as comma-separated lists (RFC 9110) for slice fields, or if the "list=true" tag option is set,
+// in which case the first item is returned for other fields. With "list=false", each header line is returned as-is.
+// The "sf=item|list|dictionary" tag option parses the value as an RFC 8941 Structured Field.
+// The "value=main|params" and "param=<name>" tag options parse "token; key=value" values like [mime.ParseMediaType],
+// returning the main value, a map[string]string of all parameters, or a single parameter.
+// Fields of type []QualityValue receive the parsed quality values, and the "negotiate=<offer1>|<offer2>" tag option
+// sets the field to the best offer for the "Accept*" header (or all acceptable ones for slice fields).
+// HTTP date headers, like "If-Modified-Since", and fields with the "layout=httpdate" tag option are not split.
+// ByteRanges, ETagList and IfRange fields receive the parsed "Range", "If-Match"/"If-None-Match" and "If-Range"
+// headers.
 type DecodeOperationHeader struct {
 }
 
@@ -13,10 +24,48 @@ func (d *DecodeOperationHeader) Decode(ctx DecodeContext, r *http.Request, isLis
 	tag *Tag) (bool, any, error) {
 	values := r.Header.Values(tag.Name)
 
+	if offers, ok := tag.Options.Get("negotiate"); ok {
+		return negotiateHeader(tag.Name, values, isList, strings.Split(offers, "|"))
+	}
+
 	if len(values) == 0 {
 		return false, nil, nil
 	}
 
+	switch typ := reflectTypeElem(field.Type()); {
+	case typ == qualityValuesType:
+		return true, ParseQualityValues(values...), nil
+	case isCombinedHeaderType(typ):
+		// parsed by the value converter.
+		return true, strings.Join(values, ", "), nil
+	}
+
+	if sfType, ok := tag.Options.Get("sf"); ok {
+		// multiple header lines are combined into a single value.
+		value, err := decodeStructuredField(ctx, sfType, reflectTypeElem(field.Type()), strings.Join(values, ","))
+		if err != nil {
+			return false, nil, err
+		}
+		return true, value, nil
+	}
+
+	param, valueType := tag.Options.Value("param", ""), tag.Options.Value("value", "")
+	if param != "" || valueType != "" {
+		return decodeHeaderParams(values, isList, valueType, param)
+	}
+
+	// JSON values and HTTP dates are never split by default.
+	list, err := tag.Options.BoolValue("list", isList && !isJSONEncoding(tag) && !isHTTPDateValue(tag))
+	if err != nil {
+		return false, nil, err
+	}
+	if list {
+		values = parseHeaderList(values)
+		if len(values) == 0 {
+			return false, nil, nil
+		}
+	}
+
 	if isList {
 		return true, values, nil
 	}
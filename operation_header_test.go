@@ -41,6 +41,118 @@ func TestDecodeHeader(t *testing.T) {
 				Val: []int32{5, 6, 7},
 			},
 		},
+		{
+			name:    "decode header with list",
+			headers: [][]string{{"Val", "gzip, br", `"a,b"`}},
+			data: &struct {
+				Val []string `inreq:"header"`
+			}{},
+			want: &struct {
+				Val []string `inreq:"header"`
+			}{
+				Val: []string{"gzip", "br", "a,b"},
+			},
+		},
+		{
+			name:    "decode header with list disabled",
+			headers: [][]string{{"Val", "gzip, br", "deflate"}},
+			data: &struct {
+				Val []string `inreq:"header,list=false"`
+			}{},
+			want: &struct {
+				Val []string `inreq:"header,list=false"`
+			}{
+				Val: []string{"gzip, br", "deflate"},
+			},
+		},
+		{
+			name:    "decode header with list option",
+			headers: [][]string{{"Val", " , gzip, br"}},
+			data: &struct {
+				Val string `inreq:"header,list=true"`
+			}{},
+			want: &struct {
+				Val string `inreq:"header,list=true"`
+			}{
+				Val: "gzip",
+			},
+		},
+		{
+			name:    "decode header with JSON slice",
+			headers: [][]string{{"Val", "[1, 2]"}},
+			data: &struct {
+				Val []int `inreq:"header,encoding=json"`
+			}{},
+			want: &struct {
+				Val []int `inreq:"header,encoding=json"`
+			}{
+				Val: []int{1, 2},
+			},
+		},
+		{
+			name:    "decode header main value",
+			headers: [][]string{{"Content-Type", "Text/HTML; charset=UTF-8"}},
+			data: &struct {
+				MediaType string `inreq:"header,name=Content-Type,value=main"`
+				Charset   string `inreq:"header,name=Content-Type,param=charset"`
+			}{},
+			want: &struct {
+				MediaType string `inreq:"header,name=Content-Type,value=main"`
+				Charset   string `inreq:"header,name=Content-Type,param=charset"`
+			}{
+				MediaType: "text/html",
+				Charset:   "UTF-8",
+			},
+		},
+		{
+			name:    "decode header params",
+			headers: [][]string{{"Content-Disposition", `attachment; filename="a b.txt"; size=10`}},
+			data: &struct {
+				Params   map[string]string `inreq:"header,name=Content-Disposition,value=params"`
+				Filename *string           `inreq:"header,name=Content-Disposition,param=FileName"`
+				Size     int               `inreq:"header,name=Content-Disposition,param=size"`
+			}{},
+			want: &struct {
+				Params   map[string]string `inreq:"header,name=Content-Disposition,value=params"`
+				Filename *string           `inreq:"header,name=Content-Disposition,param=FileName"`
+				Size     int               `inreq:"header,name=Content-Disposition,param=size"`
+			}{
+				Params:   map[string]string{"filename": "a b.txt", "size": "10"},
+				Filename: ptr("a b.txt"),
+				Size:     10,
+			},
+		},
+		{
+			name:    "decode header params with multiple lines",
+			headers: [][]string{{"Val", "a; q=1", "b", "c; q=2"}},
+			data: &struct {
+				Main []string `inreq:"header,name=Val,value=main"`
+				Q    []int    `inreq:"header,name=Val,param=q"`
+			}{},
+			want: &struct {
+				Main []string `inreq:"header,name=Val,value=main"`
+				Q    []int    `inreq:"header,name=Val,param=q"`
+			}{
+				Main: []string{"a", "b", "c"},
+				Q:    []int{1, 2},
+			},
+		},
+		{
+			name:    "decode header param not found error",
+			headers: [][]string{{"Content-Type", "text/html"}},
+			data: &struct {
+				Charset string `inreq:"header,name=Content-Type,param=charset"`
+			}{},
+			wantErr: true,
+		},
+		{
+			name:    "decode header param invalid error",
+			headers: [][]string{{"Content-Type", "text/html; charset"}},
+			data: &struct {
+				Charset string `inreq:"header,name=Content-Type,param=charset"`
+			}{},
+			wantErr: true,
+		},
 		{
 			name:    "decode header with name",
 			headers: [][]string{{"XVal", "x1"}},
@@ -140,3 +252,7 @@ func TestDecodeHeader(t *testing.T) {
 
 	require.NoError(t, nil)
 }
+
+func ptr[T any](v T) *T {
+	return &v
+}
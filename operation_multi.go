@@ -0,0 +1,105 @@
+package inreq
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// FieldSource is the source which was used to decode a multi-source field.
+type FieldSource struct {
+	FieldName string // field path, like "Filter.APIKey".
+	Operation string // operation of the source which found the value, like "header".
+	TagName   string // name used by the source, like "X-API-Key".
+}
+
+// SourceTracer is called with the source used to decode each multi-source field, like
+// `inreq:"multi,sources=header:X-API-Key|query:api_key"`.
+type SourceTracer func(source FieldSource)
+
+// multiSource is one source of a multi-source operation.
+type multiSource struct {
+	operation string
+	name      string
+}
+
+// DecodeOperationMulti is a DecodeOperation for multi-source fields, which tries each source set in the "sources"
+// tag option in order until one of them finds a value, like `inreq:"multi,sources=header:X-API-Key|query:api_key"`.
+// The source names are optional, if not set the tag name is used.
+// The source operations are the other operations of the decoder.
+// The sources can't be set in the operation itself, like `inreq:"header:X-API-Key|query:api_key"`, as the instruct
+// decoder looks up the operation of the tag by its exact name.
+type DecodeOperationMulti struct {
+	operations map[string]DecodeOperation // source operations, set by wrapDecodeOperations.
+}
+
+func (d *DecodeOperationMulti) Decode(ctx DecodeContext, r *http.Request, isList bool, field reflect.Value,
+	tag *Tag) (bool, any, error) {
+	found, value, _, err := d.decodeSource(ctx, r, isList, field, tag)
+	return found, value, err
+}
+
+// decodeSource returns the value from the first source which finds it, and the tag used for it.
+func (d *DecodeOperationMulti) decodeSource(ctx DecodeContext, r *http.Request, isList bool,
+	field reflect.Value, tag *Tag) (bool, any, *Tag, error) {
+	sources, err := parseMultiSources(tag)
+	if err != nil {
+		return false, nil, tag, err
+	}
+	for _, source := range sources {
+		operation, ok := d.operations[source.operation]
+		if !ok || source.operation == OperationMulti {
+			return false, nil, tag, fmt.Errorf("%w: unknown operation '%s' in multi-source sources '%s'",
+				ErrInvalidConfiguration, source.operation, tag.Options.Value("sources", ""))
+		}
+
+		stag := *tag
+		stag.Operation = source.operation
+		stag.Name = source.name
+
+		found, value, err := operation.Decode(ctx, r, isList, field, &stag)
+		if err != nil || found {
+			return found, value, &stag, err
+		}
+	}
+	return false, nil, tag, nil
+}
+
+// WrapRequiredError reports the operations and names of all the sources which were tried, separated by "|".
+func (d *DecodeOperationMulti) WrapRequiredError(tag *Tag, err RequiredError) error {
+	sources, perr := parseMultiSources(tag)
+	if perr != nil {
+		return err
+	}
+	operations := make([]string, len(sources))
+	names := make([]string, len(sources))
+	for i, source := range sources {
+		operations[i], names[i] = source.operation, source.name
+	}
+	err.Operation = strings.Join(operations, "|")
+	err.TagName = strings.Join(names, "|")
+	return err
+}
+
+// parseMultiSources parses the "sources" tag option in the format "operation1:name1|operation2:name2".
+func parseMultiSources(tag *Tag) ([]multiSource, error) {
+	value, ok := tag.Options.Get("sources")
+	if !ok || strings.TrimSpace(value) == "" {
+		return nil, fmt.Errorf("%w: multi-source operation requires the 'sources' tag option",
+			ErrInvalidConfiguration)
+	}
+	var ret []multiSource
+	for _, item := range strings.Split(value, "|") {
+		op, name, _ := strings.Cut(item, ":")
+		source := multiSource{
+			operation: strings.TrimSpace(op),
+			name:      strings.TrimSpace(name),
+		}
+		if source.name == "" {
+			source.name = tag.Name
+		}
+		ret = append(ret, source)
+	}
+	return ret, nil
+}
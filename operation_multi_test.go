@@ -0,0 +1,173 @@
+package inreq
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeMultiSource(t *testing.T) {
+	type DataType struct {
+		APIKey string `inreq:"multi,sources=header:X-API-Key|query:api_key|cookie:apikey"`
+	}
+
+	tests := []struct {
+		name       string
+		header     string
+		query      string
+		cookie     string
+		want       string
+		wantSource FieldSource
+		wantErr    bool
+	}{
+		{
+			name:       "header wins",
+			header:     "h1",
+			query:      "q1",
+			cookie:     "c1",
+			want:       "h1",
+			wantSource: FieldSource{FieldName: "APIKey", Operation: OperationHeader, TagName: "X-API-Key"},
+		},
+		{
+			name:       "query fallback",
+			query:      "q1",
+			cookie:     "c1",
+			want:       "q1",
+			wantSource: FieldSource{FieldName: "APIKey", Operation: OperationQuery, TagName: "api_key"},
+		},
+		{
+			name:       "cookie fallback",
+			cookie:     "c1",
+			want:       "c1",
+			wantSource: FieldSource{FieldName: "APIKey", Operation: OperationCookie, TagName: "apikey"},
+		},
+		{
+			name:    "required",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set("X-API-Key", tt.header)
+			}
+			if tt.query != "" {
+				r.URL.RawQuery = "api_key=" + tt.query
+			}
+			if tt.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: "apikey", Value: tt.cookie})
+			}
+
+			var sources []FieldSource
+			data, err := DecodeType[DataType](r, WithSourceTracer(func(source FieldSource) {
+				sources = append(sources, source)
+			}))
+			if tt.wantErr {
+				var rerr RequiredError
+				require.ErrorAs(t, err, &rerr)
+				require.Equal(t, "header|query|cookie", rerr.Operation)
+				require.Equal(t, "X-API-Key|api_key|apikey", rerr.TagName)
+				require.Empty(t, sources)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, data.APIKey)
+			require.Equal(t, []FieldSource{tt.wantSource}, sources)
+		})
+	}
+}
+
+func TestDecodeMultiSourceOptional(t *testing.T) {
+	type DataType struct {
+		Page int `inreq:"multi,sources=query|header,name=page,required=false"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Page", "12")
+
+	data := &DataType{}
+	require.NoError(t, Decode(r, data))
+	require.Equal(t, 12, data.Page)
+
+	data = &DataType{}
+	require.NoError(t, Decode(httptest.NewRequest(http.MethodGet, "/", nil), data))
+	require.Equal(t, 0, data.Page)
+}
+
+func TestDecodeMultiSourceError(t *testing.T) {
+	type DataType struct {
+		Count  int `inreq:"multi,sources=header:X-Count|query:count,max=5"`
+		Secret int `inreq:"multi,sources=query:secret|header:Authorization"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?count=10", nil)
+	r.Header.Set("Authorization", "secret-value")
+
+	err := Decode(r, &DataType{}, WithCollectErrors(true))
+	var derrs DecodeErrors
+	require.ErrorAs(t, err, &derrs)
+	require.Len(t, derrs, 2)
+
+	var verr ValidationError
+	require.ErrorAs(t, derrs[0], &verr)
+	require.Equal(t, OperationQuery, derrs[0].Operation)
+	require.Equal(t, "count", derrs[0].TagName)
+
+	require.ErrorIs(t, derrs[1], ErrCoerceInvalid)
+	require.Equal(t, OperationHeader, derrs[1].Operation)
+	require.True(t, derrs[1].Redacted)
+	require.NotContains(t, err.Error(), "secret-value")
+
+	err = Decode(r, &struct {
+		Other string `inreq:"multi,sources=query:other|unknown:other"`
+	}{}, WithCollectErrors(true))
+	require.ErrorIs(t, err, ErrInvalidConfiguration)
+	require.ErrorContains(t, err, "unknown operation 'unknown'")
+
+	err = Decode(r, &struct {
+		Value string `inreq:"multi"`
+	}{})
+	require.ErrorIs(t, err, ErrInvalidConfiguration)
+	require.ErrorContains(t, err, "requires the 'sources' tag option")
+}
+
+func TestDecoderMultiSourceMapTags(t *testing.T) {
+	type DataType struct {
+		APIKey string `inreq:"header"`
+	}
+
+	dec := NewDecoder()
+
+	var wg sync.WaitGroup
+	errs := make([]error, 10)
+	values := make([]string, 10)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r := httptest.NewRequest(http.MethodGet, "/?api_key=q1", nil)
+			data := &DataType{}
+			errs[i] = dec.Decode(r, data, WithMapTags(map[string]any{
+				"APIKey": "multi,sources=header:X-API-Key|query:api_key",
+			}))
+			values[i] = data.APIKey
+		}(i)
+	}
+	wg.Wait()
+	for i := range errs {
+		require.NoError(t, errs[i])
+		require.Equal(t, "q1", values[i])
+	}
+
+	data, err := DecodeType[DataType](httptest.NewRequest(http.MethodGet, "/?api_key=q2", nil),
+		WithMapTags(map[string]any{
+			"APIKey": "multi,sources=header:X-API-Key|query:api_key",
+		}))
+	require.NoError(t, err)
+	require.Equal(t, "q2", data.APIKey)
+}
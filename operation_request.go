@@ -0,0 +1,120 @@
+package inreq
+
+import (
+	"fmt"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+// Values of the tag name of the "request" operation.
+const (
+	RequestValueMethod        = "method"        // the request method, like "GET".
+	RequestValueHost          = "host"          // the request host, from the "Host" header.
+	RequestValueScheme        = "scheme"        // "http" or "https", from the forwarding headers of trusted proxies.
+	RequestValueURL           = "url"           // the absolute request URL, using the scheme and host.
+	RequestValuePath          = "path"          // the unescaped URL path.
+	RequestValueRawQuery      = "rawquery"      // the encoded query, without the "?".
+	RequestValueProto         = "proto"         // the protocol version, like "HTTP/1.1".
+	RequestValueRemoteAddr    = "remoteaddr"    // the network address which sent the request, usually "IP:port".
+	RequestValueContentLength = "contentlength" // the content length, which is considered not set if unknown.
+	RequestValueRequestURI    = "requesturi"    // the unmodified request target, like "/path?query".
+)
+
+var urlType = reflect.TypeOf(url.URL{})
+
+// DecodeOperationRequest is a DecodeOperation that gets metadata of the request itself, set by the tag name, like
+// `inreq:"request,name=method"`. See the RequestValue constants for the supported values.
+type DecodeOperationRequest struct {
+	TrustedProxies []netip.Prefix // proxies trusted to set the forwarding headers, see WithTrustedProxies.
+}
+
+func (d *DecodeOperationRequest) Decode(ctx DecodeContext, r *http.Request, isList bool, field reflect.Value,
+	tag *Tag) (bool, any, error) {
+	switch strings.ToLower(tag.Name) {
+	case RequestValueMethod:
+		return true, r.Method, nil
+	case RequestValueHost:
+		return true, r.Host, nil
+	case RequestValueScheme:
+		return true, requestScheme(r, d.TrustedProxies), nil
+	case RequestValueURL:
+		u := *r.URL
+		u.Scheme, u.Host = requestScheme(r, d.TrustedProxies), r.Host
+		if reflectTypeElem(field.Type()) == urlType {
+			return true, u, nil
+		}
+		return true, u.String(), nil
+	case RequestValuePath:
+		return true, r.URL.Path, nil
+	case RequestValueRawQuery:
+		return true, r.URL.RawQuery, nil
+	case RequestValueProto:
+		return true, r.Proto, nil
+	case RequestValueRemoteAddr:
+		return true, r.RemoteAddr, nil
+	case RequestValueContentLength:
+		if r.ContentLength < 0 {
+			return false, nil, nil
+		}
+		return true, r.ContentLength, nil
+	case RequestValueRequestURI:
+		if r.RequestURI != "" {
+			return true, r.RequestURI, nil
+		}
+		return true, r.URL.RequestURI(), nil
+	}
+	return false, nil, fmt.Errorf("unknown request value '%s'", tag.Name)
+}
+
+// requestScheme returns "https" for TLS requests, or "http". For requests from trusted proxies, the "proto"
+// parameter of the "Forwarded" header or the "X-Forwarded-Proto" header is used.
+func requestScheme(r *http.Request, trustedProxies []netip.Prefix) string {
+	if scheme := forwardedProto(r, trustedProxies); scheme != "" {
+		return scheme
+	}
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+// forwardedProto returns the scheme set by trusted proxies, or blank if not set or not trusted.
+// Like the "clientip" operation, "Forwarded" elements are walked from the right while they come from trusted proxies,
+// so the scheme is the one used by the client. For "X-Forwarded-Proto", the value set by the last proxy is used.
+func forwardedProto(r *http.Request, trustedProxies []netip.Prefix) string {
+	remote, ok := remoteAddr(r)
+	if !ok || !isTrustedProxy(remote, trustedProxies) {
+		return ""
+	}
+
+	if values := r.Header.Values("Forwarded"); len(values) > 0 {
+		var ret string
+		elements := parseForwarded(values)
+		for i := len(elements) - 1; i >= 0; i-- {
+			if proto := normalizeScheme(elements[i]["proto"]); proto != "" {
+				ret = proto
+			}
+			if addr, ok := parseForwardedAddr(elements[i]["for"]); !ok || !isTrustedProxy(addr, trustedProxies) {
+				break
+			}
+		}
+		return ret
+	}
+
+	if values := parseHeaderList(r.Header.Values("X-Forwarded-Proto")); len(values) > 0 {
+		return normalizeScheme(values[len(values)-1])
+	}
+	return ""
+}
+
+// normalizeScheme returns the lowercased scheme if it is "http" or "https", or blank.
+func normalizeScheme(scheme string) string {
+	switch scheme = strings.ToLower(scheme); scheme {
+	case "http", "https":
+		return scheme
+	}
+	return ""
+}
@@ -0,0 +1,131 @@
+package inreq
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeRequest(t *testing.T) {
+	type DataType struct {
+		Method        string   `inreq:"request"`
+		Host          string   `inreq:"request"`
+		Scheme        string   `inreq:"request"`
+		URL           string   `inreq:"request"`
+		URLValue      *url.URL `inreq:"request,name=url"`
+		Path          string   `inreq:"request"`
+		RawQuery      string   `inreq:"request"`
+		Proto         string   `inreq:"request"`
+		RemoteAddr    string   `inreq:"request"`
+		ContentLength int      `inreq:"request"`
+		RequestURI    string   `inreq:"request"`
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "http://example.com/a%20b?x=1", strings.NewReader("body"))
+	r.RemoteAddr = "192.0.2.1:1234"
+
+	data, err := DecodeType[DataType](r)
+	require.NoError(t, err)
+	require.Equal(t, DataType{
+		Method:        http.MethodPost,
+		Host:          "example.com",
+		Scheme:        "http",
+		URL:           "http://example.com/a%20b?x=1",
+		URLValue:      &url.URL{Scheme: "http", Host: "example.com", Path: "/a b", RawQuery: "x=1"},
+		Path:          "/a b",
+		RawQuery:      "x=1",
+		Proto:         "HTTP/1.1",
+		RemoteAddr:    "192.0.2.1:1234",
+		ContentLength: 4,
+		RequestURI:    "http://example.com/a%20b?x=1",
+	}, data)
+}
+
+func TestDecodeRequestScheme(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		tls        bool
+		headers    map[string]string
+		want       string
+	}{
+		{
+			name:       "tls",
+			remoteAddr: "192.0.2.1:1234",
+			tls:        true,
+			want:       "https",
+		},
+		{
+			name:       "untrusted proxy",
+			remoteAddr: "192.0.2.1:1234",
+			headers:    map[string]string{"X-Forwarded-Proto": "https"},
+			want:       "http",
+		},
+		{
+			name:       "forwarded proto",
+			remoteAddr: "10.0.0.1:1234",
+			headers:    map[string]string{"X-Forwarded-Proto": "http, HTTPS"},
+			want:       "https",
+		},
+		{
+			name:       "forwarded",
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string]string{
+				"Forwarded":         "for=192.0.2.60;proto=http, for=198.51.100.1;proto=https, for=10.0.0.2",
+				"X-Forwarded-Proto": "http",
+			},
+			want: "https",
+		},
+		{
+			name:       "invalid proto",
+			remoteAddr: "10.0.0.1:1234",
+			tls:        true,
+			headers:    map[string]string{"X-Forwarded-Proto": "ftp"},
+			want:       "https",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.tls {
+				r.TLS = &tls.ConnectionState{}
+			}
+			for name, value := range tt.headers {
+				r.Header.Set(name, value)
+			}
+
+			data := &struct {
+				Scheme string `inreq:"request"`
+				URL    string `inreq:"request"`
+			}{}
+			require.NoError(t, NewDecoder(WithTrustedProxies(trusted)).Decode(r, data))
+			require.Equal(t, tt.want, data.Scheme)
+			require.Equal(t, tt.want+"://example.com/", data.URL)
+		})
+	}
+}
+
+func TestDecodeRequestError(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.ContentLength = -1
+
+	err := Decode(r, &struct {
+		ContentLength int64 `inreq:"request"`
+	}{})
+	require.ErrorAs(t, err, &RequiredError{})
+
+	err = Decode(r, &struct {
+		Other string `inreq:"request"`
+	}{})
+	require.ErrorContains(t, err, "unknown request value 'other'")
+}
@@ -44,6 +44,28 @@ type BodyDecoder interface {
 	Unmarshal(ctx DecodeContext, typeParam string, r *http.Request, data any) (bool, any, error)
 }
 
+// ValueRedactor returns whether the values of a parameter are sensitive and must be redacted from errors.
+// The default one is DefaultValueRedactor.
+type ValueRedactor func(operation string, name string) bool
+
+// DefaultValueRedactor redacts the "Authorization", "Cookie" and "Proxy-Authorization" headers, and all values of
+// the "cookie" operation, as cookies usually contain session tokens.
+// Operations implementing SensitiveOperation, like "auth", are redacted regardless of the ValueRedactor.
+func DefaultValueRedactor(operation string, name string) bool {
+	switch operation {
+	case OperationCookie:
+		return true
+	case OperationHeader:
+	default:
+		return false
+	}
+	switch http.CanonicalHeaderKey(name) {
+	case "Authorization", "Cookie", "Proxy-Authorization":
+		return true
+	}
+	return false
+}
+
 // FieldNameMapper maps a struct field name to the header/query/form field name.
 // The default one uses [strings.ToLower].
 type FieldNameMapper = instruct.FieldNameMapper
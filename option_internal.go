@@ -2,9 +2,11 @@ package inreq
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/rrgmc/instruct"
 	"github.com/rrgmc/instruct/options"
+	"github.com/rrgmc/instruct/resolver"
 )
 
 const (
@@ -15,6 +17,8 @@ type sharedDefaultOptions struct {
 	sliceSplitSeparator  string        // string to be used as separator on string-to-array conversion. Default is ",".
 	pathValue            PathValue     // function used to extract the path from the request.
 	bodyDecoder          BodyDecoder   // interface to decode body to struct. Default one handles JSON and XML.
+	valueRedactor        ValueRedactor // function to check if parameter values must be redacted from errors.
+	errorMessages        ErrorMessages // localized error message templates.
 	defaultDecodeOptions decodeOptions // default decode options.
 }
 
@@ -42,9 +46,14 @@ func (d *typeDefaultOptions) apply(options ...TypeDefaultOption) {
 
 type decodeOptions struct {
 	options            instruct.DecodeOptions[*http.Request, DecodeContext]
-	allowReadBody      bool // whether operations are allowed to read the request body.
-	ensureAllQueryUsed bool // whether to check if all query parameters were used.
-	ensureAllFormUsed  bool // whether to check if all form parameters were used.
+	allowReadBody      bool         // whether operations are allowed to read the request body.
+	ensureAllQueryUsed bool         // whether to check if all query parameters were used.
+	ensureAllFormUsed  bool         // whether to check if all form parameters were used.
+	collectErrors      bool         // whether to continue decoding on field errors, returning all of them.
+	language           string       // language for error messages. If blank, uses the "Accept-Language" header.
+	sourceTracer       SourceTracer // function called with the source used by each multi-source field.
+	mergeMode          MergeMode    // how decoded values are set on fields which already contain a value.
+	bodyFields         bool         // whether to always record the fields present in JSON bodies.
 }
 
 func (d *decodeOptions) apply(options ...DecodeOption) {
@@ -63,17 +72,25 @@ func defaultSharedDefaultOptions() sharedDefaultOptions {
 	ret := sharedDefaultOptions{
 		sliceSplitSeparator:  ",",
 		bodyDecoder:          NewDefaultBodyDecoder(),
+		valueRedactor:        DefaultValueRedactor,
 		defaultDecodeOptions: defaultDecodeOptions(),
 	}
 	return ret
 }
 
+// newDefaultResolver returns the default Resolver, which also parses time.Time values using [time.RFC3339].
+func newDefaultResolver() Resolver {
+	return resolver.NewResolver(resolver.WithValueResolver(resolver.NewDefaultValueResolver(
+		resolver.WithCustomTypes(resolver.NewValueResolverTime(time.RFC3339)))))
+}
+
 func defaultDefaultOptions() defaultOptions {
 	ret := defaultOptions{
 		options:              instruct.NewDefaultOptions[*http.Request, DecodeContext](),
 		sharedDefaultOptions: defaultSharedDefaultOptions(),
 	}
 	ret.options.TagName = DefaultTagName
+	ret.options.Resolver = newDefaultResolver()
 	return ret
 }
 
@@ -83,6 +100,7 @@ func defaultTypeDefaultOptions() typeDefaultOptions {
 		sharedDefaultOptions: defaultSharedDefaultOptions(),
 	}
 	ret.options.TagName = DefaultTagName
+	ret.options.Resolver = newDefaultResolver()
 	return ret
 }
 
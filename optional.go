@@ -0,0 +1,172 @@
+package inreq
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+
+	"github.com/rrgmc/instruct"
+)
+
+// Optional is a field type which records whether the parameter was present in the request, so that an absent
+// parameter can be distinguished from one set to the zero value.
+// It can be used with any operation, and is not required by default. It can be made required with the
+// "required=true" tag option.
+// JSON null, and values equal to the "null" tag option (like "?name=null" with "null=null", or "?name=" with
+// "null="), set IsNull.
+type Optional[T any] struct {
+	value T
+	set   bool
+	null  bool
+}
+
+// NewOptional returns an Optional set to the value.
+func NewOptional[T any](value T) Optional[T] {
+	return Optional[T]{value: value, set: true}
+}
+
+// Value returns the value, or the zero value if not set or null.
+func (o Optional[T]) Value() T {
+	return o.value
+}
+
+// IsSet returns whether the parameter was present in the request, even if empty.
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// IsNull returns whether the parameter was present with the null marker set in the "null" tag option, or with null
+// in JSON.
+func (o Optional[T]) IsNull() bool {
+	return o.null
+}
+
+func (o *Optional[T]) UnmarshalJSON(data []byte) error {
+	var value T
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		*o = Optional[T]{value: value, set: true, null: true}
+		return nil
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*o = Optional[T]{value: value, set: true}
+	return nil
+}
+
+func (o Optional[T]) MarshalJSON() ([]byte, error) {
+	if !o.set || o.null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(o.value)
+}
+
+func (o *Optional[T]) optionalValue() reflect.Value {
+	return reflect.ValueOf(&o.value).Elem()
+}
+
+func (o *Optional[T]) setOptional(null bool) {
+	o.set = true
+	o.null = null
+	if null {
+		var value T
+		o.value = value
+	}
+}
+
+// optionalField is implemented by Optional to allow the decoder to set it.
+type optionalField interface {
+	optionalValue() reflect.Value
+	setOptional(null bool)
+}
+
+var optionalFieldType = reflect.TypeOf(new(optionalField)).Elem()
+
+// asOptional returns the Optional interface if the field is an Optional.
+func asOptional(field reflect.Value) (optionalField, bool) {
+	if !field.CanAddr() {
+		return nil, false
+	}
+	o, ok := field.Addr().Interface().(optionalField)
+	return o, ok
+}
+
+// fieldValue returns the value to be checked of the field, which is the inner value for Optional fields.
+func fieldValue(field reflect.Value) reflect.Value {
+	if o, ok := asOptional(field); ok {
+		return o.optionalValue()
+	}
+	return field
+}
+
+// isListField returns whether the field should receive a list of values, using the same rule as the instruct
+// decoder: only slices/arrays of primitive types, otherwise "type UUID [16]byte" would be checked as an array.
+func isListField(field reflect.Value) bool {
+	return field.Type().PkgPath() == "" && (field.Kind() == reflect.Slice || field.Kind() == reflect.Array)
+}
+
+// isNullValue returns whether the value returned by an operation is the null marker set in the "null" tag option.
+func isNullValue(tag *Tag, value any) bool {
+	marker, ok := tag.Options.Get("null")
+	if !ok {
+		return false
+	}
+	switch v := value.(type) {
+	case string:
+		return v == marker
+	case []string:
+		return len(v) == 1 && v[0] == marker
+	}
+	return false
+}
+
+// newOptionalRequiredDecoder returns a decoder which parses the tags with DefaultRequired set to false, so the
+// Required flag of the tags of Optional fields is only set if the "required" tag option is set explicitly.
+// Its operations only record these fields, see findOptionalRequired.
+func newOptionalRequiredDecoder(o instruct.DefaultOptions[*http.Request, DecodeContext]) *instruct.Decoder[*http.Request, DecodeContext] {
+	o.DefaultRequired = false
+	o.StructInfoCache(false)
+	operations := map[string]DecodeOperation{}
+	for name := range o.DecodeOperations {
+		operations[name] = optionalRequiredOperation{}
+	}
+	o.DecodeOperations = operations
+	return instruct.NewDecoder[*http.Request, DecodeContext](o)
+}
+
+// findOptionalRequired returns the paths of the Optional fields of the type with the "required" tag option set.
+func findOptionalRequired(dec *instruct.Decoder[*http.Request, DecodeContext], typ reflect.Type,
+	mapTags MapTags) map[string]bool {
+	data := reflect.New(reflectTypeElem(typ))
+	ctx := &optionalRequiredContext{
+		decodeContext: &decodeContext{data: data},
+		required:      map[string]bool{},
+	}
+	// the tags were already parsed successfully by the decoder, so no error is expected.
+	_ = dec.Decode(nil, data.Interface(), instruct.DecodeOptions[*http.Request, DecodeContext]{
+		Ctx:     ctx,
+		MapTags: mapTags,
+	})
+	return ctx.required
+}
+
+// optionalRequiredContext is the decode context of the decoder returned by newOptionalRequiredDecoder.
+type optionalRequiredContext struct {
+	*decodeContext
+	required map[string]bool
+}
+
+// optionalRequiredOperation records the Optional fields with the Required tag flag set.
+type optionalRequiredOperation struct {
+}
+
+func (o optionalRequiredOperation) Decode(ctx DecodeContext, r *http.Request, isList bool, field reflect.Value,
+	tag *Tag) (bool, any, error) {
+	if c, ok := ctx.(*optionalRequiredContext); ok && tag.Required {
+		if _, ok := asOptional(field); ok {
+			c.required[c.FieldPath(field)] = true
+		}
+	}
+	return true, IgnoreDecodeValue, nil
+}
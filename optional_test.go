@@ -0,0 +1,168 @@
+package inreq
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeOptional(t *testing.T) {
+	type Body struct {
+		Name  Optional[string] `json:"name"`
+		Email Optional[string] `json:"email"`
+		Age   Optional[int]    `json:"age"`
+	}
+
+	type DataType struct {
+		Page   Optional[int]      `inreq:"query"`
+		Sort   Optional[string]   `inreq:"query"`
+		Limit  Optional[int]      `inreq:"query"`
+		Order  Optional[int]      `inreq:"query,null="`
+		Filter Optional[string]   `inreq:"query,null=null"`
+		Tags   Optional[[]string] `inreq:"query,name=tag"`
+		Token  Optional[string]   `inreq:"header,name=X-Token"`
+		APIKey Optional[string]   `inreq:"multi,sources=header:X-API-Key|query:api_key"`
+		Body   Optional[Body]     `inreq:"body"`
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/?page=0&sort=&order=&filter=null&tag=a&tag=b&api_key=k1",
+		strings.NewReader(`{"name":"John","email":null}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	data, err := DecodeType[DataType](r)
+	require.NoError(t, err)
+
+	require.True(t, data.Page.IsSet())
+	require.False(t, data.Page.IsNull())
+	require.Equal(t, 0, data.Page.Value())
+
+	require.Equal(t, NewOptional(""), data.Sort)
+
+	require.True(t, data.Order.IsSet())
+	require.True(t, data.Order.IsNull())
+	require.True(t, data.Filter.IsSet())
+	require.True(t, data.Filter.IsNull())
+
+	require.False(t, data.Limit.IsSet())
+	require.False(t, data.Token.IsSet())
+
+	require.True(t, data.Tags.IsSet())
+	require.Equal(t, []string{"a", "b"}, data.Tags.Value())
+
+	require.Equal(t, "k1", data.APIKey.Value())
+
+	require.True(t, data.Body.IsSet())
+	body := data.Body.Value()
+	require.Equal(t, NewOptional("John"), body.Name)
+	require.True(t, body.Email.IsSet())
+	require.True(t, body.Email.IsNull())
+	require.False(t, body.Age.IsSet())
+}
+
+func TestDecodeOptionalRequired(t *testing.T) {
+	type Filter struct {
+		Owner Optional[int] `inreq:"query,required=true"`
+	}
+
+	type DataType struct {
+		Page   Optional[int] `inreq:"query,required=true"`
+		Sort   Optional[int] `inreq:"query"`
+		Limit  Optional[int] `inreq:"query,required=false"`
+		Filter *Filter       `inreq:"recurse"`
+	}
+
+	for _, test := range []struct {
+		name    string
+		options []AnyOption
+		query   string
+		want    string
+	}{
+		{
+			name:  "default required",
+			query: "owner=1",
+			want:  "Page",
+		},
+		{
+			name:    "default not required",
+			options: []AnyOption{WithDefaultRequired(false)},
+			query:   "owner=1",
+			want:    "Page",
+		},
+		{
+			name:  "nested",
+			query: "page=1",
+			want:  "Filter.Owner",
+		},
+		{
+			name: "map tags",
+			options: []AnyOption{WithMapTags(map[string]any{
+				"Sort": "query,required=true",
+			})},
+			query: "page=1&owner=1",
+			want:  "Sort",
+		},
+		{
+			name: "default map tags",
+			options: []AnyOption{WithDefaultMapTags(DataType{}, map[string]any{
+				"Sort": "query,required=true",
+			})},
+			query: "page=1&owner=1",
+			want:  "Sort",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/?"+test.query, nil)
+
+			err := Decode(r, &DataType{}, test.options...)
+			var rerr RequiredError
+			require.ErrorAs(t, err, &rerr)
+			require.Equal(t, test.want, rerr.FieldName)
+		})
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?page=1&owner=1", nil)
+
+	data, err := DecodeType[DataType](r)
+	require.NoError(t, err)
+	require.Equal(t, NewOptional(1), data.Page)
+	require.False(t, data.Sort.IsSet())
+}
+
+func TestDecodeOptionalError(t *testing.T) {
+	type DataType struct {
+		Page Optional[int]    `inreq:"query"`
+		Name Optional[string] `inreq:"query,min=3"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?page=x&name=ab", nil)
+
+	err := Decode(r, &DataType{}, WithCollectErrors(true))
+	var derrs DecodeErrors
+	require.ErrorAs(t, err, &derrs)
+	require.Len(t, derrs, 2)
+	require.Equal(t, "Page", derrs[0].FieldName)
+	require.ErrorIs(t, derrs[0], ErrCoerceInvalid)
+	require.Equal(t, "Name", derrs[1].FieldName)
+	var verr ValidationError
+	require.ErrorAs(t, derrs[1], &verr)
+}
+
+func TestOptionalJSON(t *testing.T) {
+	var v struct {
+		A Optional[int] `json:"a"`
+		B Optional[int] `json:"b"`
+		C Optional[int] `json:"c"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"a":1,"b":null}`), &v))
+	require.Equal(t, NewOptional(1), v.A)
+	require.True(t, v.B.IsNull())
+	require.False(t, v.C.IsSet())
+
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"a":1,"b":null,"c":null}`, string(data))
+}
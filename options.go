@@ -2,6 +2,7 @@ package inreq
 
 import (
 	"net/http"
+	"net/netip"
 	"reflect"
 
 	"github.com/rrgmc/instruct"
@@ -49,7 +50,58 @@ func WithBodyDecoder(bodyDecoder BodyDecoder) DefaultAndTypeDefaultOption {
 	})
 }
 
-// WithDefaultDecodeOperations adds the default operations (query, path, header, form and body).
+// WithValueRedactor sets the function used to check if parameter values must be redacted from errors.
+// The default is DefaultValueRedactor, which can be called from custom functions to keep its rules.
+func WithValueRedactor(valueRedactor ValueRedactor) DefaultAndTypeDefaultOption {
+	return defaultAndTypeDefaultSharedOptionFunc(func(o *sharedDefaultOptions) {
+		o.valueRedactor = valueRedactor
+	})
+}
+
+// WithErrorMessages sets the message templates used to render localized error messages. The language is set
+// by WithLanguage, or if not set, from the request "Accept-Language" header.
+// If no message is found for the language, the default (English) error message is used.
+func WithErrorMessages(errorMessages ErrorMessages) DefaultAndTypeDefaultOption {
+	return defaultAndTypeDefaultSharedOptionFunc(func(o *sharedDefaultOptions) {
+		o.errorMessages = errorMessages
+	})
+}
+
+// WithTrustedProxies sets the proxies trusted to set the forwarding headers on the "clientip" and "request"
+// operations, which must be added before it (the non-"Custom" calls add them first).
+// The default is none, so the client IP is always the address of the request.
+func WithTrustedProxies(trustedProxies []netip.Prefix) DefaultAndTypeDefaultOption {
+	return defaultAndTypeDefaultOptionFunc(func(o *instruct.DefaultOptions[*http.Request, DecodeContext]) {
+		if op, ok := o.DecodeOperations[OperationClientIP].(*DecodeOperationClientIP); ok {
+			o.DecodeOperations[OperationClientIP] = &DecodeOperationClientIP{
+				TrustedProxies: trustedProxies,
+				Headers:        op.Headers,
+			}
+		}
+		if _, ok := o.DecodeOperations[OperationRequest].(*DecodeOperationRequest); ok {
+			o.DecodeOperations[OperationRequest] = &DecodeOperationRequest{
+				TrustedProxies: trustedProxies,
+			}
+		}
+	})
+}
+
+// WithClientIPHeaders sets the headers checked by the "clientip" operation, in order of precedence, which must be
+// added before it (the non-"Custom" calls add it first).
+// The default is DefaultClientIPHeaders.
+func WithClientIPHeaders(headers ...string) DefaultAndTypeDefaultOption {
+	return defaultAndTypeDefaultOptionFunc(func(o *instruct.DefaultOptions[*http.Request, DecodeContext]) {
+		if op, ok := o.DecodeOperations[OperationClientIP].(*DecodeOperationClientIP); ok {
+			o.DecodeOperations[OperationClientIP] = &DecodeOperationClientIP{
+				TrustedProxies: op.TrustedProxies,
+				Headers:        headers,
+			}
+		}
+	})
+}
+
+// WithDefaultDecodeOperations adds the default operations (query, path, header, form, body, cookie, auth, clientip
+// and request).
 // If the non-"Custom" calls are used, this option is added by default.
 func WithDefaultDecodeOperations() DefaultAndTypeDefaultOption {
 	return defaultAndTypeDefaultOptionFunc(func(o *instruct.DefaultOptions[*http.Request, DecodeContext]) {
@@ -58,6 +110,11 @@ func WithDefaultDecodeOperations() DefaultAndTypeDefaultOption {
 		o.DecodeOperations[OperationHeader] = &DecodeOperationHeader{}
 		o.DecodeOperations[OperationForm] = &DecodeOperationForm{}
 		o.DecodeOperations[OperationBody] = &DecodeOperationBody{}
+		o.DecodeOperations[OperationCookie] = &DecodeOperationCookie{}
+		o.DecodeOperations[OperationAuth] = &DecodeOperationAuth{}
+		o.DecodeOperations[OperationClientIP] = &DecodeOperationClientIP{}
+		o.DecodeOperations[OperationRequest] = &DecodeOperationRequest{}
+		o.DecodeOperations[OperationMulti] = &DecodeOperationMulti{}
 	})
 }
 
@@ -130,6 +187,56 @@ func WithEnsureAllFormUsed(ensureAllFormUsed bool) FullOption {
 	})
 }
 
+// WithCollectErrors sets whether to continue decoding when a field fails, returning a DecodeErrors with all the
+// errors found. Default is false, which returns the first error.
+func WithCollectErrors(collectErrors bool) FullOption {
+	return fullSharedOptionFunc(func(o *sharedDefaultOptions) {
+		o.defaultDecodeOptions.collectErrors = collectErrors
+	}, func(o *decodeOptions) {
+		o.collectErrors = collectErrors
+	})
+}
+
+// WithLanguage sets the language of the error messages set with WithErrorMessages, instead of using the
+// request "Accept-Language" header.
+func WithLanguage(language string) FullOption {
+	return fullSharedOptionFunc(func(o *sharedDefaultOptions) {
+		o.defaultDecodeOptions.language = language
+	}, func(o *decodeOptions) {
+		o.language = language
+	})
+}
+
+// WithSourceTracer sets a function to be called with the source which was used to decode each multi-source
+// field, like `inreq:"multi,sources=header:X-API-Key|query:api_key"`.
+func WithSourceTracer(sourceTracer SourceTracer) FullOption {
+	return fullSharedOptionFunc(func(o *sharedDefaultOptions) {
+		o.defaultDecodeOptions.sourceTracer = sourceTracer
+	}, func(o *decodeOptions) {
+		o.sourceTracer = sourceTracer
+	})
+}
+
+// WithMergeMode sets how decoded values are set on fields which already contain a value, like in structs pre-filled
+// from configuration or defaults. The default is MergeModeDefault.
+func WithMergeMode(mergeMode MergeMode) FullOption {
+	return fullSharedOptionFunc(func(o *sharedDefaultOptions) {
+		o.defaultDecodeOptions.mergeMode = mergeMode
+	}, func(o *decodeOptions) {
+		o.mergeMode = mergeMode
+	})
+}
+
+// WithBodyFields sets whether to record the fields present in JSON bodies for DecodeContext.BodyFields even if the
+// body struct doesn't have a BodyFields field, which requires keeping a copy of the body. Default is false.
+func WithBodyFields(bodyFields bool) FullOption {
+	return fullSharedOptionFunc(func(o *sharedDefaultOptions) {
+		o.defaultDecodeOptions.bodyFields = bodyFields
+	}, func(o *decodeOptions) {
+		o.bodyFields = bodyFields
+	})
+}
+
 // WithMapTags sets decode-operation-specific MapTags. These override the default cached struct information
 // but don't change the original one. This should be used to override configurations on each call.
 func WithMapTags(tags MapTags) TypeDefaultAndDecodeOption {
@@ -0,0 +1,473 @@
+package inreq
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Patch media types.
+const (
+	MergePatchMediaType = "application/merge-patch+json" // RFC 7386
+	JSONPatchMediaType  = "application/json-patch+json"  // RFC 6902
+)
+
+// JSON Patch operations.
+const (
+	PatchOpAdd     = "add"
+	PatchOpRemove  = "remove"
+	PatchOpReplace = "replace"
+	PatchOpMove    = "move"
+	PatchOpCopy    = "copy"
+	PatchOpTest    = "test"
+)
+
+var (
+	ErrPatchInvalidOperation = errors.New("invalid patch operation")
+	ErrPatchTestFailed       = errors.New("patch test failed")
+	ErrPatchPath             = errors.New("invalid patch path")
+	ErrPatchValue            = errors.New("patched value is invalid for the target type")
+)
+
+// PatchError is returned when applying a patch fails. Use errors.Is with ErrPatchInvalidOperation,
+// ErrPatchTestFailed, ErrPatchPath and ErrPatchValue to check the cause.
+type PatchError struct {
+	Index int    // index of the JSON Patch operation, -1 if not related to a single operation (like merge patches).
+	Op    string // JSON Patch operation.
+	Path  string // JSON pointer of the operation.
+	Err   error
+}
+
+func (e PatchError) Error() string {
+	if e.Index < 0 {
+		return fmt.Sprintf("patch: %s", e.Err)
+	}
+	return fmt.Sprintf("patch operation %d ('%s' at '%s'): %s", e.Index, e.Op, e.Path, e.Err)
+}
+
+func (e PatchError) Unwrap() error {
+	return e.Err
+}
+
+// MergePatch is a body field type which is decoded from an RFC 7386 "application/merge-patch+json" body, and can
+// be applied to an existing value.
+type MergePatch[T any] struct {
+	data json.RawMessage
+}
+
+// Raw returns the patch document.
+func (p MergePatch[T]) Raw() json.RawMessage {
+	return p.data
+}
+
+// Apply applies the patch to the target value. The target is converted to JSON, patched, and converted back into
+// a new value, so fields which are not serialized to JSON are reset.
+func (p MergePatch[T]) Apply(target *T) error {
+	var patch any
+	if err := patchUnmarshal(p.data, &patch); err != nil {
+		return PatchError{Index: -1, Err: err}
+	}
+	return applyPatchDocument(target, func(doc any) (any, error) {
+		return mergePatch(doc, patch), nil
+	}, func(err error) error {
+		return PatchError{Index: -1, Err: err}
+	})
+}
+
+func (p *MergePatch[T]) patchMediaType() string {
+	return MergePatchMediaType
+}
+
+func (p *MergePatch[T]) decodePatch(data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	p.data = data
+	return nil
+}
+
+// mergePatch applies an RFC 7386 merge patch.
+func mergePatch(target any, patch any) any {
+	p, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]any)
+	if !ok {
+		t = map[string]any{}
+	}
+	for key, value := range p {
+		if value == nil {
+			delete(t, key)
+		} else {
+			t[key] = mergePatch(t[key], value)
+		}
+	}
+	return t
+}
+
+// JSONPatch is a body field type which is decoded from an RFC 6902 "application/json-patch+json" body, and can
+// be applied to an existing value.
+type JSONPatch[T any] []JSONPatchOperation
+
+// JSONPatchOperation is a single RFC 6902 operation.
+type JSONPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  *string         `json:"from,omitempty"` // nil if not set, as "" is the root pointer.
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Apply applies the patch to the target value. The target is converted to JSON, patched, and converted back into
+// a new value, so fields which are not serialized to JSON are reset.
+// The patch is atomic: if any operation fails, the target is not changed.
+func (p JSONPatch[T]) Apply(target *T) error {
+	if err := p.validate(); err != nil {
+		return err
+	}
+	return applyPatchDocument(target, func(doc any) (any, error) {
+		for i, op := range p {
+			var err error
+			if doc, err = op.apply(doc); err != nil {
+				return nil, PatchError{Index: i, Op: op.Op, Path: op.Path, Err: err}
+			}
+		}
+		return doc, nil
+	}, func(err error) error {
+		return PatchError{Index: -1, Err: err}
+	})
+}
+
+func (p *JSONPatch[T]) patchMediaType() string {
+	return JSONPatchMediaType
+}
+
+func (p *JSONPatch[T]) decodePatch(data []byte) error {
+	var ops JSONPatch[T]
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return err
+	}
+	if err := ops.validate(); err != nil {
+		return err
+	}
+	*p = ops
+	return nil
+}
+
+// validate checks if the operations are valid, without applying them.
+func (p JSONPatch[T]) validate() error {
+	for i, op := range p {
+		var err error
+		switch op.Op {
+		case PatchOpAdd, PatchOpReplace, PatchOpTest:
+			if op.Value == nil {
+				err = fmt.Errorf("%w: missing 'value'", ErrPatchInvalidOperation)
+			}
+		case PatchOpMove, PatchOpCopy:
+			if op.From == nil {
+				err = fmt.Errorf("%w: missing 'from'", ErrPatchInvalidOperation)
+			} else if _, perr := parseJSONPointer(*op.From); perr != nil {
+				err = perr
+			} else if op.Op == PatchOpMove && strings.HasPrefix(op.Path+"/", *op.From+"/") && op.Path != *op.From {
+				err = fmt.Errorf("%w: cannot move a value into one of its children", ErrPatchInvalidOperation)
+			}
+		case PatchOpRemove:
+		default:
+			err = fmt.Errorf("%w: unknown operation '%s'", ErrPatchInvalidOperation, op.Op)
+		}
+		if err == nil {
+			_, err = parseJSONPointer(op.Path)
+		}
+		if err != nil {
+			return PatchError{Index: i, Op: op.Op, Path: op.Path, Err: err}
+		}
+	}
+	return nil
+}
+
+func (o JSONPatchOperation) apply(doc any) (any, error) {
+	path, _ := parseJSONPointer(o.Path)
+
+	switch o.Op {
+	case PatchOpAdd:
+		var value any
+		if err := patchUnmarshal(o.Value, &value); err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, path, value)
+	case PatchOpRemove:
+		ret, _, err := patchRemove(doc, path)
+		return ret, err
+	case PatchOpReplace:
+		var value any
+		if err := patchUnmarshal(o.Value, &value); err != nil {
+			return nil, err
+		}
+		if len(path) == 0 {
+			// replacing the root replaces the whole document.
+			return value, nil
+		}
+		ret, _, err := patchRemove(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(ret, path, value)
+	case PatchOpMove:
+		from, _ := parseJSONPointer(*o.From)
+		if *o.From == o.Path {
+			// moving a value to the same location doesn't change the document, but it must exist.
+			_, err := patchGet(doc, from)
+			return doc, err
+		}
+		ret, value, err := patchRemove(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(ret, path, value)
+	case PatchOpCopy:
+		from, _ := parseJSONPointer(*o.From)
+		value, err := patchGet(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if value, err = patchClone(value); err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, path, value)
+	case PatchOpTest:
+		var value any
+		if err := patchUnmarshal(o.Value, &value); err != nil {
+			return nil, err
+		}
+		current, err := patchGet(doc, path)
+		if err != nil {
+			return nil, err
+		}
+		if !patchEqual(current, value) {
+			return nil, ErrPatchTestFailed
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("%w: unknown operation '%s'", ErrPatchInvalidOperation, o.Op)
+}
+
+// patchBody is implemented by body field types which are decoded from patch media types.
+type patchBody interface {
+	patchMediaType() string
+	decodePatch(data []byte) error
+}
+
+var patchBodyType = reflect.TypeOf(new(patchBody)).Elem()
+
+// applyPatchDocument converts the target to a JSON document, calls apply on it, and converts it back into the
+// target.
+func applyPatchDocument(target any, apply func(doc any) (any, error), wrapErr func(err error) error) error {
+	tv := reflect.ValueOf(target)
+	if tv.Kind() != reflect.Pointer || tv.IsNil() {
+		return errors.New("patch target must be a non-nil pointer")
+	}
+
+	data, err := json.Marshal(target)
+	if err != nil {
+		return err
+	}
+	var doc any
+	if err = patchUnmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	if doc, err = apply(doc); err != nil {
+		return err
+	}
+
+	if data, err = json.Marshal(doc); err != nil {
+		return err
+	}
+	value := reflect.New(tv.Type().Elem())
+	if err = json.Unmarshal(data, value.Interface()); err != nil {
+		return wrapErr(fmt.Errorf("%w: %w", ErrPatchValue, err))
+	}
+	tv.Elem().Set(value.Elem())
+	return nil
+}
+
+// patchUnmarshal decodes JSON keeping numbers as json.Number, so they are not changed by the patch.
+func patchUnmarshal(data []byte, v any) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
+
+// parseJSONPointer parses an RFC 6901 JSON pointer into its reference tokens.
+func parseJSONPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("%w: '%s' must start with '/'", ErrPatchPath, pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+	}
+	return tokens, nil
+}
+
+func patchGet(doc any, path []string) (any, error) {
+	for _, token := range path {
+		switch c := doc.(type) {
+		case map[string]any:
+			value, ok := c[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: '%s' not found", ErrPatchPath, token)
+			}
+			doc = value
+		case []any:
+			i, err := patchIndex(token, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = c[i]
+		default:
+			return nil, fmt.Errorf("%w: '%s' is not a container", ErrPatchPath, token)
+		}
+	}
+	return doc, nil
+}
+
+// patchUpdate calls update on the parent container of the path, setting the returned container in its place.
+func patchUpdate(doc any, path []string, update func(parent any, key string) (any, error)) (any, error) {
+	if len(path) == 1 {
+		return update(doc, path[0])
+	}
+	child, err := patchGet(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	if child, err = patchUpdate(child, path[1:], update); err != nil {
+		return nil, err
+	}
+	switch c := doc.(type) {
+	case map[string]any:
+		c[path[0]] = child
+	case []any:
+		i, _ := patchIndex(path[0], len(c)-1)
+		c[i] = child
+	}
+	return doc, nil
+}
+
+func patchAdd(doc any, path []string, value any) (any, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	return patchUpdate(doc, path, func(parent any, key string) (any, error) {
+		switch c := parent.(type) {
+		case map[string]any:
+			c[key] = value
+			return c, nil
+		case []any:
+			if key == "-" {
+				return append(c, value), nil
+			}
+			i, err := patchIndex(key, len(c))
+			if err != nil {
+				return nil, err
+			}
+			c = append(c, nil)
+			copy(c[i+1:], c[i:])
+			c[i] = value
+			return c, nil
+		}
+		return nil, fmt.Errorf("%w: '%s' is not a container", ErrPatchPath, key)
+	})
+}
+
+func patchRemove(doc any, path []string) (any, any, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("%w: cannot remove the root", ErrPatchPath)
+	}
+	var removed any
+	ret, err := patchUpdate(doc, path, func(parent any, key string) (any, error) {
+		switch c := parent.(type) {
+		case map[string]any:
+			value, ok := c[key]
+			if !ok {
+				return nil, fmt.Errorf("%w: '%s' not found", ErrPatchPath, key)
+			}
+			removed = value
+			delete(c, key)
+			return c, nil
+		case []any:
+			i, err := patchIndex(key, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+			removed = c[i]
+			return append(c[:i:i], c[i+1:]...), nil
+		}
+		return nil, fmt.Errorf("%w: '%s' is not a container", ErrPatchPath, key)
+	})
+	return ret, removed, err
+}
+
+// patchIndex parses an array index, which must be between 0 and max.
+func patchIndex(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: invalid array index '%s'", ErrPatchPath, token)
+	}
+	return i, nil
+}
+
+func patchClone(value any) (any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var ret any
+	err = patchUnmarshal(data, &ret)
+	return ret, err
+}
+
+// patchEqual compares JSON values, comparing numbers by value.
+func patchEqual(a, b any) bool {
+	switch av := a.(type) {
+	case json.Number:
+		bv, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		af, aerr := av.Float64()
+		bf, berr := bv.Float64()
+		return av == bv || (aerr == nil && berr == nil && af == bf)
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for key, value := range av {
+			if bvalue, ok := bv[key]; !ok || !patchEqual(value, bvalue) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		bv, ok := b.([]any)
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !patchEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
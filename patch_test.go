@@ -0,0 +1,221 @@
+package inreq
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testPatchAddress struct {
+	Street string `json:"street,omitempty"`
+	Number int    `json:"number,omitempty"`
+}
+
+type testPatchEntity struct {
+	Name    string            `json:"name"`
+	Email   *string           `json:"email,omitempty"`
+	Tags    []string          `json:"tags,omitempty"`
+	Address *testPatchAddress `json:"address,omitempty"`
+	Count   int64             `json:"count"`
+}
+
+func testPatchNewEntity() testPatchEntity {
+	email := "john@example.com"
+	return testPatchEntity{
+		Name:    "John",
+		Email:   &email,
+		Tags:    []string{"a", "b"},
+		Address: &testPatchAddress{Street: "Main", Number: 1},
+		Count:   9007199254740993,
+	}
+}
+
+func TestDecodeMergePatch(t *testing.T) {
+	type DataType struct {
+		Patch MergePatch[testPatchEntity] `inreq:"body"`
+	}
+
+	r := httptest.NewRequest(http.MethodPatch, "/",
+		strings.NewReader(`{"name":"Mary","email":null,"address":{"number":2}}`))
+	r.Header.Set("Content-Type", MergePatchMediaType)
+
+	data, err := DecodeType[DataType](r)
+	require.NoError(t, err)
+
+	entity := testPatchNewEntity()
+	require.NoError(t, data.Patch.Apply(&entity))
+	require.Equal(t, testPatchEntity{
+		Name:    "Mary",
+		Tags:    []string{"a", "b"},
+		Address: &testPatchAddress{Street: "Main", Number: 2},
+		Count:   9007199254740993,
+	}, entity)
+
+	r = httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"name":1}`))
+	r.Header.Set("Content-Type", MergePatchMediaType)
+	data, err = DecodeType[DataType](r)
+	require.NoError(t, err)
+
+	entity = testPatchNewEntity()
+	err = data.Patch.Apply(&entity)
+	var perr PatchError
+	require.ErrorAs(t, err, &perr)
+	require.ErrorIs(t, err, ErrPatchValue)
+	require.Equal(t, testPatchNewEntity(), entity)
+}
+
+func TestDecodeMergePatchPointer(t *testing.T) {
+	type DataType struct {
+		Patch *MergePatch[testPatchEntity] `inreq:"body,required=false"`
+	}
+
+	r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`{"name":"Mary"}`))
+	r.Header.Set("Content-Type", MergePatchMediaType)
+	data, err := DecodeType[DataType](r)
+	require.NoError(t, err)
+	require.NotNil(t, data.Patch)
+
+	entity := testPatchNewEntity()
+	require.NoError(t, data.Patch.Apply(&entity))
+	require.Equal(t, "Mary", entity.Name)
+
+	data, err = DecodeType[DataType](httptest.NewRequest(http.MethodPatch, "/", nil))
+	require.NoError(t, err)
+	require.Nil(t, data.Patch)
+}
+
+func TestDecodePatchMediaType(t *testing.T) {
+	type DataType struct {
+		Patch JSONPatch[testPatchEntity] `inreq:"body"`
+	}
+
+	r := httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`[]`))
+	r.Header.Set("Content-Type", "application/json")
+	_, err := DecodeType[DataType](r)
+	var merr UnsupportedMediaTypeError
+	require.ErrorAs(t, err, &merr)
+
+	r = httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`[{"op":"delete","path":"/name"}]`))
+	r.Header.Set("Content-Type", JSONPatchMediaType)
+	_, err = DecodeType[DataType](r)
+	var berr BodyDecodeError
+	require.ErrorAs(t, err, &berr)
+	require.ErrorIs(t, err, ErrPatchInvalidOperation)
+
+	r = httptest.NewRequest(http.MethodPatch, "/", strings.NewReader(`[{"op":"remove","path":"/name"}]`))
+	data, err := DecodeType[DataType](r, WithMapTags(map[string]any{
+		"Patch": "body,type=json",
+	}))
+	require.NoError(t, err)
+	require.Equal(t, JSONPatch[testPatchEntity]{{Op: PatchOpRemove, Path: "/name"}}, data.Patch)
+}
+
+func TestJSONPatchApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   string
+		want    func(e *testPatchEntity)
+		wantErr error
+	}{
+		{
+			name:  "add and replace",
+			patch: `[{"op":"add","path":"/tags/1","value":"x"},{"op":"add","path":"/tags/-","value":"z"},{"op":"replace","path":"/name","value":"Mary"}]`,
+			want: func(e *testPatchEntity) {
+				e.Tags = []string{"a", "x", "b", "z"}
+				e.Name = "Mary"
+			},
+		},
+		{
+			name:  "remove",
+			patch: `[{"op":"remove","path":"/email"},{"op":"remove","path":"/tags/0"}]`,
+			want: func(e *testPatchEntity) {
+				e.Email = nil
+				e.Tags = []string{"b"}
+			},
+		},
+		{
+			name:  "move and copy",
+			patch: `[{"op":"copy","from":"/address/street","path":"/name"},{"op":"move","from":"/tags/0","path":"/tags/-"}]`,
+			want: func(e *testPatchEntity) {
+				e.Name = "Main"
+				e.Tags = []string{"b", "a"}
+			},
+		},
+		{
+			name:  "test",
+			patch: `[{"op":"test","path":"/address","value":{"number":1.0,"street":"Main"}},{"op":"test","path":"/count","value":9007199254740993}]`,
+			want:  func(e *testPatchEntity) {},
+		},
+		{
+			name:    "test failed",
+			patch:   `[{"op":"replace","path":"/name","value":"Mary"},{"op":"test","path":"/name","value":"John"}]`,
+			wantErr: ErrPatchTestFailed,
+		},
+		{
+			name:    "path not found",
+			patch:   `[{"op":"replace","path":"/address/city","value":"X"}]`,
+			wantErr: ErrPatchPath,
+		},
+		{
+			name:    "invalid index",
+			patch:   `[{"op":"add","path":"/tags/5","value":"x"}]`,
+			wantErr: ErrPatchPath,
+		},
+		{
+			name:    "invalid operation",
+			patch:   `[{"op":"add","path":"/name"}]`,
+			wantErr: ErrPatchInvalidOperation,
+		},
+		{
+			name:  "replace root",
+			patch: `[{"op":"replace","path":"","value":{"name":"Mary","count":1}}]`,
+			want: func(e *testPatchEntity) {
+				*e = testPatchEntity{Name: "Mary", Count: 1}
+			},
+		},
+		{
+			name:  "move to same location",
+			patch: `[{"op":"move","from":"/name","path":"/name"}]`,
+			want:  func(e *testPatchEntity) {},
+		},
+		{
+			name:    "move without from",
+			patch:   `[{"op":"move","path":"/name"}]`,
+			wantErr: ErrPatchInvalidOperation,
+		},
+		{
+			name:    "copy without from",
+			patch:   `[{"op":"copy","path":"/name"}]`,
+			wantErr: ErrPatchInvalidOperation,
+		},
+		{
+			name:    "move into child",
+			patch:   `[{"op":"move","from":"/address","path":"/address/street"}]`,
+			wantErr: ErrPatchInvalidOperation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var patch JSONPatch[testPatchEntity]
+			require.NoError(t, patchUnmarshal([]byte(tt.patch), &patch))
+
+			entity := testPatchNewEntity()
+			err := patch.Apply(&entity)
+			if tt.wantErr != nil {
+				var perr PatchError
+				require.ErrorAs(t, err, &perr)
+				require.ErrorIs(t, err, tt.wantErr)
+				require.Equal(t, testPatchNewEntity(), entity)
+				return
+			}
+			require.NoError(t, err)
+			want := testPatchNewEntity()
+			tt.want(&want)
+			require.Equal(t, want, entity)
+		})
+	}
+}
@@ -0,0 +1,2 @@
+// Package problem converts inreq decode errors into RFC 9457 "application/problem+json" documents.
+package problem
@@ -0,0 +1,275 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/rrgmc/inreq"
+)
+
+// ContentType is the RFC 9457 problem details media type.
+const ContentType = "application/problem+json"
+
+// Details is an RFC 9457 problem details document.
+type Details struct {
+	Type          string         `json:"type,omitempty"`
+	Title         string         `json:"title,omitempty"`
+	Status        int            `json:"status,omitempty"`
+	Detail        string         `json:"detail,omitempty"`
+	Instance      string         `json:"instance,omitempty"`
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"` // "invalid-params" extension member.
+
+	// Challenges are the "WWW-Authenticate" header challenges, set for "401 Unauthorized" errors.
+	Challenges []string `json:"-"`
+}
+
+// InvalidParam describes an offending request parameter in the "invalid-params" extension member.
+type InvalidParam struct {
+	Name    string `json:"name"`
+	In      string `json:"in,omitempty"`      // parameter location: "query", "header", "path", "form" or "body".
+	Pointer string `json:"pointer,omitempty"` // JSON pointer inside the body, if known.
+	Reason  string `json:"reason"`            // localized FieldError message if available, or a default English reason.
+}
+
+// Generic details, used instead of the error message which may contain internal information.
+const (
+	DetailInvalidParams = "The request contains invalid parameters."
+	DetailInternalError = "The request could not be decoded."
+)
+
+// New converts an inreq decode error into a problem details document.
+// Errors which are not decode errors (like invalid struct configurations) are returned as "500 Internal Server
+// Error".
+// The error message is only used as the detail for request-level errors (like "401 Unauthorized"). For "400 Bad
+// Request" and "422 Unprocessable Entity" the detail is DetailInvalidParams, and each parameter is listed in
+// InvalidParams, and for server errors it is DetailInternalError.
+func New(err error) *Details {
+	ret := &Details{
+		Type:   "about:blank",
+		Status: Status(err),
+	}
+	ret.Title = http.StatusText(ret.Status)
+	switch ret.Status {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		ret.Detail = DetailInvalidParams
+		ret.InvalidParams = invalidParams(err)
+	case http.StatusUnauthorized:
+		ret.Detail = err.Error()
+		ret.Challenges = challenges(err)
+	default:
+		if ret.Status >= http.StatusInternalServerError {
+			ret.Detail = DetailInternalError
+		} else {
+			ret.Detail = err.Error()
+		}
+	}
+	return ret
+}
+
+// Write writes the problem details document for the error to the response.
+func Write(w http.ResponseWriter, err error) error {
+	return New(err).Write(w)
+}
+
+// Write writes the problem details document to the response.
+func (d *Details) Write(w http.ResponseWriter) error {
+	w.Header().Set("Content-Type", ContentType)
+	for _, challenge := range d.Challenges {
+		w.Header().Add("WWW-Authenticate", challenge)
+	}
+	w.WriteHeader(d.Status)
+	return json.NewEncoder(w).Encode(d)
+}
+
+// Status returns the HTTP status code for an inreq decode error.
+//   - 401 Unauthorized: the credentials of an "auth" field are missing or invalid.
+//   - 413 Request Entity Too Large: the body was larger than the limit set by [http.MaxBytesReader].
+//   - 415 Unsupported Media Type: the body media type is not supported.
+//   - 406 Not Acceptable: none of the offers of a "negotiate" header field is acceptable.
+//   - 409 Conflict: a JSON Patch "test" operation failed (from [inreq.JSONPatch.Apply]).
+//   - 422 Unprocessable Entity: all errors are validation errors, or a patch could not be applied.
+//   - 400 Bad Request: required, coercion, enum, unused values, body parsing and invalid patch operation errors.
+//   - 500 Internal Server Error: any other error, like errors in the struct configuration.
+func Status(err error) int {
+	var derrs inreq.DecodeErrors
+	if errors.As(err, &derrs) {
+		ret := 0
+		for _, ferr := range derrs {
+			ret = mergeStatus(ret, errorStatus(ferr))
+		}
+		return ret
+	}
+	return errorStatus(err)
+}
+
+// mergeStatus returns the status of a list of errors. Request-level errors take precedence, and validation errors
+// are only returned if all errors are validation errors.
+func mergeStatus(current, status int) int {
+	for _, s := range []int{http.StatusInternalServerError, http.StatusUnauthorized, http.StatusRequestEntityTooLarge,
+		http.StatusUnsupportedMediaType, http.StatusNotAcceptable, http.StatusConflict, http.StatusBadRequest} {
+		if current == s || status == s {
+			return s
+		}
+	}
+	return status
+}
+
+func errorStatus(err error) int {
+	var maxBytesErr *http.MaxBytesError
+	var mediaTypeErr inreq.UnsupportedMediaTypeError
+	var notAcceptableErr inreq.NotAcceptableError
+	var unauthorizedErr inreq.UnauthorizedError
+	var validationErr inreq.ValidationError
+	var requiredErr inreq.RequiredError
+	var coerceErr inreq.CoerceError
+	var notUsedErr inreq.ValuesNotUsedError
+	var bodyErr inreq.BodyDecodeError
+	var enumErr inreq.EnumError
+
+	switch {
+	case errors.As(err, &unauthorizedErr):
+		return http.StatusUnauthorized
+	case errors.As(err, &maxBytesErr):
+		return http.StatusRequestEntityTooLarge
+	case errors.As(err, &mediaTypeErr):
+		return http.StatusUnsupportedMediaType
+	case errors.As(err, &notAcceptableErr):
+		return http.StatusNotAcceptable
+	case errors.As(err, &validationErr):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, inreq.ErrPatchTestFailed):
+		return http.StatusConflict
+	case errors.Is(err, inreq.ErrPatchPath), errors.Is(err, inreq.ErrPatchValue):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, inreq.ErrPatchInvalidOperation):
+		return http.StatusBadRequest
+	case errors.As(err, &requiredErr), errors.As(err, &coerceErr), errors.As(err, &notUsedErr),
+		errors.As(err, &bodyErr), errors.As(err, &enumErr):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// challenges returns the "WWW-Authenticate" challenges of the errors, without duplicates.
+func challenges(err error) []string {
+	errs := []error{err}
+	var derrs inreq.DecodeErrors
+	if errors.As(err, &derrs) {
+		errs = errs[:0]
+		for _, ferr := range derrs {
+			errs = append(errs, ferr)
+		}
+	}
+
+	var ret []string
+	seen := map[string]bool{}
+	for _, e := range errs {
+		var unauthorizedErr inreq.UnauthorizedError
+		if errors.As(e, &unauthorizedErr) {
+			if challenge := unauthorizedErr.Challenge(); !seen[challenge] {
+				seen[challenge] = true
+				ret = append(ret, challenge)
+			}
+		}
+	}
+	return ret
+}
+
+// invalidParams builds the list of offending parameters.
+func invalidParams(err error) []InvalidParam {
+	var derrs inreq.DecodeErrors
+	if errors.As(err, &derrs) {
+		var ret []InvalidParam
+		for _, ferr := range derrs {
+			if p, ok := invalidParam(ferr); ok {
+				ret = append(ret, p)
+			}
+		}
+		return ret
+	}
+	if p, ok := invalidParam(err); ok {
+		return []InvalidParam{p}
+	}
+	return nil
+}
+
+func invalidParam(err error) (InvalidParam, bool) {
+	var fieldErr inreq.FieldError
+	var requiredErr inreq.RequiredError
+	var validationErr inreq.ValidationError
+	var notUsedErr inreq.ValuesNotUsedError
+	var bodyErr inreq.BodyDecodeError
+	var enumErr inreq.EnumError
+	var jsonTypeErr *json.UnmarshalTypeError
+
+	var ret InvalidParam
+	if errors.As(err, &fieldErr) {
+		ret.Name = fieldErr.TagName
+		ret.In = fieldErr.Operation
+	}
+
+	switch {
+	case errors.As(err, &requiredErr):
+		ret.Name = requiredErr.TagName
+		ret.In = requiredErr.Operation
+		ret.Reason = "is required"
+	case errors.As(err, &validationErr):
+		ret.Name = validationErr.TagName
+		ret.In = validationErr.Operation
+		ret.Reason = validationReason(validationErr)
+	case errors.As(err, &notUsedErr):
+		ret.In = notUsedErr.Operation
+		ret.Reason = "contains unknown parameters"
+	case errors.As(err, &enumErr):
+		ret.Reason = fmt.Sprintf("must be one of %s", strings.Join(enumErr.Allowed, ", "))
+	case errors.As(err, &jsonTypeErr):
+		ret.In = inreq.OperationBody
+		ret.Name = jsonTypeErr.Field
+		ret.Pointer = jsonPointer(jsonTypeErr.Field)
+		ret.Reason = fmt.Sprintf("must be of type %s", jsonTypeErr.Type.String())
+	case errors.As(err, &bodyErr):
+		ret.In = inreq.OperationBody
+		ret.Reason = "is invalid"
+	case ret.In != "":
+		ret.Reason = "is invalid"
+	default:
+		return ret, false
+	}
+	if fieldErr.Message != "" {
+		ret.Reason = fieldErr.Message
+	}
+	return ret, true
+}
+
+// jsonPointer converts a dot-separated [json.UnmarshalTypeError] field path into an RFC 6901 JSON pointer.
+func jsonPointer(field string) string {
+	if field == "" {
+		return ""
+	}
+	return inreq.JSONPointer(strings.Split(field, ".")...)
+}
+
+func validationReason(err inreq.ValidationError) string {
+	switch err.Rule {
+	case inreq.ValidateNonEmpty:
+		return "must not be empty"
+	case inreq.ValidateLen:
+		return fmt.Sprintf("must have length %s", err.Param)
+	case inreq.ValidateMin:
+		return fmt.Sprintf("must be at least %s", err.Param)
+	case inreq.ValidateMax:
+		return fmt.Sprintf("must be at most %s", err.Param)
+	case inreq.ValidatePattern:
+		return fmt.Sprintf("must match the pattern %s", err.Param)
+	case inreq.ValidateOneOf:
+		return fmt.Sprintf("must be one of %s", strings.ReplaceAll(err.Param, "|", ", "))
+	case inreq.ValidateEmail:
+		return "must be a valid e-mail address"
+	case inreq.ValidateUUID:
+		return "must be a valid UUID"
+	}
+	return fmt.Sprintf("failed validation '%s'", err.Rule)
+}
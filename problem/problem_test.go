@@ -0,0 +1,319 @@
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/rrgmc/inreq"
+	"github.com/rrgmc/instruct/types"
+	"github.com/stretchr/testify/require"
+)
+
+type testSort string
+
+func (testSort) Values() []string {
+	return []string{"asc", "desc"}
+}
+
+func TestNew(t *testing.T) {
+	type Body struct {
+		Name    string
+		Address struct {
+			Number int `json:"number"`
+		} `json:"address"`
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		target      func() any
+		options     []inreq.AnyOption
+		maxBytes    int64
+		want        *Details
+	}{
+		{
+			name: "required and coerce errors",
+			target: func() any {
+				return &struct {
+					Page  int    `inreq:"query"`
+					Token string `inreq:"header,name=X-Token"`
+					Count int    `inreq:"query,max=5"`
+				}{}
+			},
+			options: []inreq.AnyOption{inreq.WithCollectErrors(true)},
+			want: &Details{
+				Status: http.StatusBadRequest,
+				Detail: DetailInvalidParams,
+				InvalidParams: []InvalidParam{
+					{Name: "page", In: "query", Reason: "is invalid"},
+					{Name: "X-Token", In: "header", Reason: "is required"},
+					{Name: "count", In: "query", Reason: "must be at most 5"},
+				},
+			},
+		},
+		{
+			name: "validation errors",
+			target: func() any {
+				return &struct {
+					Count int    `inreq:"query,max=5"`
+					Sort  string `inreq:"query,oneof=asc|desc"`
+				}{}
+			},
+			options: []inreq.AnyOption{inreq.WithCollectErrors(true)},
+			want: &Details{
+				Status: http.StatusUnprocessableEntity,
+				Detail: DetailInvalidParams,
+				InvalidParams: []InvalidParam{
+					{Name: "count", In: "query", Reason: "must be at most 5"},
+					{Name: "sort", In: "query", Reason: "must be one of asc, desc"},
+				},
+			},
+		},
+		{
+			name: "enum error",
+			target: func() any {
+				return &struct {
+					Sort testSort `inreq:"query"`
+				}{}
+			},
+			want: &Details{
+				Status: http.StatusBadRequest,
+				Detail: DetailInvalidParams,
+				InvalidParams: []InvalidParam{
+					{Name: "sort", In: "query", Reason: "must be one of asc, desc"},
+				},
+			},
+		},
+		{
+			name: "single validation error",
+			target: func() any {
+				return &struct {
+					Count int `inreq:"query,max=5"`
+				}{}
+			},
+			want: &Details{
+				Status: http.StatusUnprocessableEntity,
+				Detail: DetailInvalidParams,
+				InvalidParams: []InvalidParam{
+					{Name: "count", In: "query", Reason: "must be at most 5"},
+				},
+			},
+		},
+		{
+			name:        "unsupported media type",
+			contentType: "text/csv",
+			body:        "a,b",
+			target: func() any {
+				return &struct {
+					Body Body `inreq:"body"`
+				}{}
+			},
+			want: &Details{
+				Status: http.StatusUnsupportedMediaType,
+			},
+		},
+		{
+			name:        "body too large",
+			contentType: "application/json",
+			body:        `{"Name":"long name"}`,
+			maxBytes:    5,
+			target: func() any {
+				return &struct {
+					Body Body `inreq:"body"`
+				}{}
+			},
+			want: &Details{
+				Status: http.StatusRequestEntityTooLarge,
+			},
+		},
+		{
+			name:        "body type error",
+			contentType: "application/json",
+			body:        `{"address":{"number":"x"}}`,
+			target: func() any {
+				return &struct {
+					Body Body `inreq:"body"`
+				}{}
+			},
+			want: &Details{
+				Status: http.StatusBadRequest,
+				Detail: DetailInvalidParams,
+				InvalidParams: []InvalidParam{
+					{Name: "address.number", In: "body", Pointer: "/address/number", Reason: "must be of type int"},
+				},
+			},
+		},
+		{
+			name: "configuration error",
+			target: func() any {
+				return &struct {
+					Page int `inreq:"unknown"`
+				}{}
+			},
+			want: &Details{
+				Status: http.StatusInternalServerError,
+				Detail: DetailInternalError,
+			},
+		},
+		{
+			name:        "field configuration error",
+			contentType: "application/json",
+			body:        `{"Name":"John"}`,
+			target: func() any {
+				return &struct {
+					Body  Body `inreq:"body"`
+					Body2 Body `inreq:"body"`
+				}{}
+			},
+			want: &Details{
+				Status: http.StatusInternalServerError,
+				Detail: DetailInternalError,
+			},
+		},
+	}
+
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/?page=x&count=10&sort=up", strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				r.Header.Set("Content-Type", tt.contentType)
+			}
+			if tt.maxBytes > 0 {
+				r.Body = http.MaxBytesReader(httptest.NewRecorder(), r.Body, tt.maxBytes)
+			}
+
+			err := inreq.Decode(r, tt.target(), tt.options...)
+			require.Error(t, err)
+
+			d := New(err)
+			require.Equal(t, "about:blank", d.Type)
+			require.Equal(t, http.StatusText(tt.want.Status), d.Title)
+			require.Equal(t, tt.want.Status, d.Status)
+			if tt.want.Detail == "" {
+				require.Equal(t, err.Error(), d.Detail)
+			} else {
+				require.Equal(t, tt.want.Detail, d.Detail)
+			}
+			require.Equal(t, tt.want.InvalidParams, d.InvalidParams)
+		})
+	}
+}
+
+func TestWrite(t *testing.T) {
+	w := httptest.NewRecorder()
+	err := Write(w, inreq.FieldError{
+		Operation: inreq.OperationPath,
+		FieldName: "ID",
+		TagName:   "id",
+		Err:       types.NewCoerceError(errors.New("invalid id")),
+	})
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	require.Equal(t, ContentType, w.Header().Get("Content-Type"))
+
+	var d map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &d))
+	require.Equal(t, []any{
+		map[string]any{"name": "id", "in": "path", "reason": "is invalid"},
+	}, d["invalid-params"])
+}
+
+func TestStatusNotAcceptable(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "text/html")
+
+	err := inreq.Decode(r, &struct {
+		Type string `inreq:"header,name=Accept,negotiate=application/json"`
+	}{})
+	require.Error(t, err)
+
+	d := New(err)
+	require.Equal(t, http.StatusNotAcceptable, d.Status)
+	require.Empty(t, d.InvalidParams)
+}
+
+func TestWriteUnauthorized(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/?page=x", nil)
+
+	err := inreq.Decode(r, &struct {
+		Token string `inreq:"auth,scheme=bearer,realm=api"`
+		Key   string `inreq:"auth,scheme=ApiKey,header=X-Api-Key"`
+		Page  int    `inreq:"query"`
+	}{}, inreq.WithCollectErrors(true))
+	require.Error(t, err)
+
+	w := httptest.NewRecorder()
+	require.NoError(t, Write(w, err))
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Equal(t, []string{`Bearer realm="api"`, "ApiKey"}, w.Header().Values("WWW-Authenticate"))
+
+	var d map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &d))
+	require.NotContains(t, d, "invalid-params")
+}
+
+func TestStatusConfiguration(t *testing.T) {
+	err := inreq.FieldError{
+		Operation: inreq.OperationPath,
+		FieldName: "ID",
+		TagName:   "id",
+		Err:       errors.New("path value function not set"),
+	}
+	require.Equal(t, http.StatusInternalServerError, Status(err))
+
+	d := New(err)
+	require.Equal(t, DetailInternalError, d.Detail)
+	require.Empty(t, d.InvalidParams)
+}
+
+func TestStatusMerge(t *testing.T) {
+	conflict := inreq.FieldError{Err: inreq.PatchError{Err: inreq.ErrPatchTestFailed}}
+	required := inreq.FieldError{Err: inreq.RequiredError{}}
+	require.Equal(t, http.StatusConflict, Status(inreq.DecodeErrors{required, conflict}))
+	require.Equal(t, http.StatusConflict, Status(inreq.DecodeErrors{conflict, required}))
+}
+
+func TestStatusPatch(t *testing.T) {
+	type Entity struct {
+		Name string `json:"name"`
+	}
+	entity := Entity{Name: "John"}
+
+	for _, tt := range []struct {
+		patch string
+		want  int
+	}{
+		{patch: `[{"op":"test","path":"/name","value":"Mary"}]`, want: http.StatusConflict},
+		{patch: `[{"op":"remove","path":"/email"}]`, want: http.StatusUnprocessableEntity},
+		{patch: `[{"op":"unknown","path":"/name"}]`, want: http.StatusBadRequest},
+	} {
+		var patch inreq.JSONPatch[Entity]
+		require.NoError(t, json.Unmarshal([]byte(tt.patch), &patch))
+		require.Equal(t, tt.want, Status(patch.Apply(&entity)))
+	}
+}
+
+func TestStatusSensitiveBody(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"A":"secret-value"}`))
+	r.Header.Set("Content-Type", "application/json")
+
+	err := inreq.Decode(r, &struct {
+		Body struct {
+			A int
+		} `inreq:"body,sensitive=true"`
+	}{})
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "secret-value")
+
+	var bodyErr inreq.BodyDecodeError
+	require.ErrorAs(t, err, &bodyErr)
+	var typeErr *json.UnmarshalTypeError
+	require.ErrorAs(t, err, &typeErr)
+	require.Equal(t, http.StatusBadRequest, Status(err))
+}
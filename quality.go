@@ -0,0 +1,191 @@
+package inreq
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// QualityValue is an item of a header using quality values (RFC 9110, section 12.4.2), like "Accept",
+// "Accept-Language", "Accept-Encoding" and "Accept-Charset".
+// Fields of type []QualityValue receive the header items sorted by quality, in descending order.
+type QualityValue struct {
+	Value  string            // item value, like "text/html", "pt-BR" or "gzip".
+	Q      float64           // quality value, from 0 to 1. Default is 1.
+	Params map[string]string // other parameters, with lowercase names. Nil if there are none.
+}
+
+var qualityValuesType = reflect.TypeOf([]QualityValue{})
+
+// ParseQualityValues parses the header values, returning the items sorted by quality in descending order.
+// Items with the same quality keep the header order. Items with quality 0 ("not acceptable") are kept.
+func ParseQualityValues(values ...string) []QualityValue {
+	var ret []QualityValue
+	for _, item := range parseHeaderList(values) {
+		if qv, ok := parseQualityValue(item); ok {
+			ret = append(ret, qv)
+		}
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		return ret[i].Q > ret[j].Q
+	})
+	return ret
+}
+
+// parseQualityValue parses a single item, like "text/html;level=1;q=0.5". Invalid quality values are ignored.
+func parseQualityValue(item string) (QualityValue, bool) {
+	parts := splitHeaderQuoted(item, ';')
+	ret := QualityValue{
+		Value: strings.TrimSpace(parts[0]),
+		Q:     1,
+	}
+	if ret.Value == "" {
+		return ret, false
+	}
+	for _, part := range parts[1:] {
+		name, value, _ := strings.Cut(part, "=")
+		name, value = strings.ToLower(strings.TrimSpace(name)), strings.TrimSpace(value)
+		if name == "" {
+			continue
+		}
+		if s, ok := unquoteHeaderString(value); ok {
+			value = s
+		}
+		if name == "q" {
+			if q, err := strconv.ParseFloat(value, 64); err == nil && q >= 0 && q <= 1 {
+				ret.Q = q
+			}
+			continue
+		}
+		if ret.Params == nil {
+			ret.Params = map[string]string{}
+		}
+		ret.Params[name] = value
+	}
+	return ret, true
+}
+
+// A NotAcceptableError is returned by the "negotiate" header tag option when none of the offers is acceptable.
+type NotAcceptableError struct {
+	Header string
+	Offers []string
+}
+
+func (e NotAcceptableError) Error() string {
+	return fmt.Sprintf("none of the offers (%s) is acceptable for header '%s'", strings.Join(e.Offers, ", "),
+		e.Header)
+}
+
+// negotiateHeader returns the acceptable offers for the header values, sorted by the client preference, and then by
+// the offers order. If the header is not present, all offers are acceptable.
+func negotiateHeader(header string, values []string, isList bool, offers []string) (bool, any, error) {
+	acceptable := offers
+	if len(values) > 0 {
+		acceptable = negotiate(header, ParseQualityValues(values...), offers)
+	}
+	if len(acceptable) == 0 {
+		return false, nil, NotAcceptableError{
+			Header: http.CanonicalHeaderKey(header),
+			Offers: offers,
+		}
+	}
+	if isList {
+		return true, acceptable, nil
+	}
+	return true, acceptable[0], nil
+}
+
+// negotiate returns the acceptable offers, sorted by the quality of the most specific item matching each one.
+func negotiate(header string, accepted []QualityValue, offers []string) []string {
+	match := qualityMatcher(header)
+
+	type offerq struct {
+		offer string
+		q     float64
+	}
+	var matches []offerq
+	for _, offer := range offers {
+		q, specificity := 0.0, -1
+		for _, a := range accepted {
+			if s, ok := match(a, offer); ok && s > specificity {
+				q, specificity = a.Q, s
+			}
+		}
+		if specificity < 0 && strings.EqualFold(header, "Accept-Encoding") && strings.EqualFold(offer, "identity") {
+			// "identity" is always acceptable, unless excluded (RFC 9110, section 12.5.3).
+			q = 0.001
+		}
+		if q > 0 {
+			matches = append(matches, offerq{offer: offer, q: q})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].q > matches[j].q
+	})
+
+	ret := make([]string, 0, len(matches))
+	for _, m := range matches {
+		ret = append(ret, m.offer)
+	}
+	return ret
+}
+
+// qualityMatcher returns a function which checks if an offer matches an accepted item, returning the match
+// specificity, where higher values are more specific.
+func qualityMatcher(header string) func(accepted QualityValue, offer string) (int, bool) {
+	switch http.CanonicalHeaderKey(header) {
+	case "Accept":
+		return matchMediaRange
+	case "Accept-Language":
+		return matchLanguageRange
+	}
+	return func(accepted QualityValue, offer string) (int, bool) {
+		switch {
+		case accepted.Value == "*":
+			return 0, true
+		case strings.EqualFold(accepted.Value, offer):
+			return 1, true
+		}
+		return 0, false
+	}
+}
+
+// matchMediaRange matches media ranges, like "text/*" or "text/html;level=1" (RFC 9110, section 12.5.1).
+func matchMediaRange(accepted QualityValue, offer string) (int, bool) {
+	offerValue, _ := parseQualityValue(offer)
+	otype, osubtype, _ := strings.Cut(offerValue.Value, "/")
+	atype, asubtype, _ := strings.Cut(accepted.Value, "/")
+
+	switch {
+	case atype == "*" && asubtype == "*":
+		return 0, true
+	case !strings.EqualFold(atype, otype):
+		return 0, false
+	case asubtype == "*":
+		return 1, true
+	case !strings.EqualFold(asubtype, osubtype):
+		return 0, false
+	}
+	for name, value := range accepted.Params {
+		if ovalue, ok := offerValue.Params[name]; !ok || !strings.EqualFold(value, ovalue) {
+			return 0, false
+		}
+	}
+	return 2 + len(accepted.Params), true
+}
+
+// matchLanguageRange matches language ranges using the RFC 4647 basic filtering, where "en" matches "en-US".
+func matchLanguageRange(accepted QualityValue, offer string) (int, bool) {
+	switch {
+	case accepted.Value == "*":
+		return 0, true
+	case strings.EqualFold(accepted.Value, offer),
+		len(offer) > len(accepted.Value) && offer[len(accepted.Value)] == '-' &&
+			strings.EqualFold(offer[:len(accepted.Value)], accepted.Value):
+		return len(accepted.Value), true
+	}
+	return 0, false
+}
@@ -0,0 +1,89 @@
+package inreq
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQualityValues(t *testing.T) {
+	require.Equal(t, []QualityValue{
+		{Value: "text/html", Q: 1, Params: map[string]string{"level": "1"}},
+		{Value: "application/json", Q: 1},
+		{Value: "text/*", Q: 0.5},
+		{Value: "*/*", Q: 0.1, Params: map[string]string{"x": "a;b"}},
+		{Value: "image/png", Q: 0},
+	}, ParseQualityValues(`text/*;q=0.5, text/html;level=1, image/png;q=0`, `*/*; q=0.1; x="a;b", application/json;q=2`))
+	require.Empty(t, ParseQualityValues(" , "))
+}
+
+func TestDecodeHeaderQualityValues(t *testing.T) {
+	type DataType struct {
+		Accept         []QualityValue `inreq:"header"`
+		AcceptLanguage []QualityValue `inreq:"header,name=Accept-Language"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "text/plain;q=0.5, application/json")
+	r.Header.Set("Accept-Language", "pt-BR, en;q=0.8")
+
+	data, err := DecodeType[DataType](r)
+	require.NoError(t, err)
+	require.Equal(t, []QualityValue{
+		{Value: "application/json", Q: 1},
+		{Value: "text/plain", Q: 0.5},
+	}, data.Accept)
+	require.Equal(t, []QualityValue{
+		{Value: "pt-BR", Q: 1},
+		{Value: "en", Q: 0.8},
+	}, data.AcceptLanguage)
+}
+
+func TestDecodeHeaderNegotiate(t *testing.T) {
+	type DataType struct {
+		Type      string   `inreq:"header,name=Accept,negotiate=application/json|text/html|text/plain"`
+		Types     []string `inreq:"header,name=Accept,negotiate=application/json|text/html|text/plain"`
+		Language  string   `inreq:"header,name=Accept-Language,negotiate=en-US|pt-BR|es"`
+		Encoding  string   `inreq:"header,name=Accept-Encoding,negotiate=br|gzip|identity"`
+		Charset   string   `inreq:"header,name=Accept-Charset,negotiate=utf-8|iso-8859-1"`
+		Default   string   `inreq:"header,name=X-Accept,negotiate=a|b"`
+		Identity  string   `inreq:"header,name=Accept-Encoding,negotiate=zstd|identity"`
+		Unordered []string `inreq:"header,name=Accept-Language,negotiate=es|en-US|pt-BR"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "text/*;q=0.8, text/html, application/*;q=0")
+	r.Header.Set("Accept-Language", "pt;q=0.9, en;q=0.5, *;q=0.1")
+	r.Header.Set("Accept-Encoding", "gzip, br;q=0.5")
+	r.Header.Set("Accept-Charset", "ISO-8859-1, *;q=0.5")
+
+	data, err := DecodeType[DataType](r)
+	require.NoError(t, err)
+	require.Equal(t, "text/html", data.Type)
+	require.Equal(t, []string{"text/html", "text/plain"}, data.Types)
+	require.Equal(t, "pt-BR", data.Language)
+	require.Equal(t, "gzip", data.Encoding)
+	require.Equal(t, "iso-8859-1", data.Charset)
+	require.Equal(t, "a", data.Default)
+	require.Equal(t, "identity", data.Identity)
+	require.Equal(t, []string{"pt-BR", "en-US", "es"}, data.Unordered)
+}
+
+func TestDecodeHeaderNegotiateError(t *testing.T) {
+	type DataType struct {
+		Type string `inreq:"header,name=Accept,negotiate=application/json|application/xml"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept", "text/html, application/*;q=0")
+
+	err := Decode(r, &DataType{})
+	var naErr NotAcceptableError
+	require.ErrorAs(t, err, &naErr)
+	require.Equal(t, NotAcceptableError{
+		Header: "Accept",
+		Offers: []string{"application/json", "application/xml"},
+	}, naErr)
+}
@@ -0,0 +1,100 @@
+package inreq
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/rrgmc/instruct/types"
+)
+
+// valueConverter converts a string returned by an operation into a value of the target type, using the tag
+// options, before it is sent to the Resolver. The target type never is a pointer.
+// Returns false if the converter doesn't handle the type or the tag options.
+type valueConverter func(tag *Tag, typ reflect.Type, value string) (any, bool, error)
+
+// valueConverters is the list of converters, in order of precedence.
+var valueConverters = []valueConverter{
+	convertJSONValue,
+	convertEnumValue,
+	convertTimeValue,
+	convertDurationValue,
+	convertTimeLocationValue,
+	convertBytesValue,
+	convertHeaderTypeValue,
+	convertTextValue,
+}
+
+// convertFieldValue converts the string values returned by operations into the field type using the tag options,
+// like "layout" for time.Time. Values which are not handled are returned unchanged, to be resolved by the Resolver.
+func convertFieldValue(tag *Tag, typ reflect.Type, value any) (any, error) {
+	typ = reflectTypeElem(typ)
+	switch v := value.(type) {
+	case string:
+		if c, ok, err := convertStringValue(tag, typ, v); err != nil || ok {
+			return c, err
+		}
+	case []string:
+		if isJSONEncoding(tag) {
+			// JSON values are decoded from a single value.
+			if len(v) != 1 {
+				return nil, types.NewCoerceError(fmt.Errorf("%w: expected a single JSON value, got %d",
+					ErrCoerceInvalid, len(v)))
+			}
+			c, _, err := convertStringValue(tag, typ, v[0])
+			return c, err
+		}
+		if isByteSlice(typ) {
+			// byte slices are decoded from a single value.
+			if len(v) != 1 {
+				return value, nil
+			}
+			if c, ok, err := convertStringValue(tag, typ, v[0]); err != nil || ok {
+				return c, err
+			}
+			return value, nil
+		}
+		if typ.PkgPath() != "" || (typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array) {
+			return value, nil
+		}
+		elemType := reflectTypeElem(typ.Elem())
+		ret := make([]any, 0, len(v))
+		for _, item := range v {
+			c, ok, err := convertStringValue(tag, elemType, item)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				return value, nil
+			}
+			ret = append(ret, c)
+		}
+		return ret, nil
+	}
+	return value, nil
+}
+
+func convertStringValue(tag *Tag, typ reflect.Type, value string) (any, bool, error) {
+	for _, converter := range valueConverters {
+		c, ok, err := converter(tag, typ, value)
+		if err != nil {
+			if errors.Is(err, ErrInvalidConfiguration) {
+				// errors in the tag options are not caused by the value.
+				return nil, false, err
+			}
+			return nil, false, types.NewCoerceError(fmt.Errorf("%w: %w", ErrCoerceInvalid, err))
+		}
+		if ok {
+			return c, true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// reflectTypeElem returns the type pointed to, if it is a pointer.
+func reflectTypeElem(typ reflect.Type) reflect.Type {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	return typ
+}
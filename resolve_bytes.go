@@ -0,0 +1,49 @@
+package inreq
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Values of the "encoding" tag option for byte slices.
+const (
+	EncodingBase64    = "base64"    // standard base64, padding is optional.
+	EncodingBase64URL = "base64url" // URL-safe base64, padding is optional.
+	EncodingHex       = "hex"
+)
+
+// convertBytesValue decodes byte slices using the "encoding" tag option.
+func convertBytesValue(tag *Tag, typ reflect.Type, value string) (any, bool, error) {
+	if !isByteSlice(typ) {
+		return nil, false, nil
+	}
+	encoding, ok := tag.Options.Get("encoding")
+	if !ok {
+		return nil, false, nil
+	}
+
+	var b []byte
+	var err error
+	switch encoding {
+	case EncodingBase64:
+		b, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(value, "="))
+	case EncodingBase64URL:
+		b, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(value, "="))
+	case EncodingHex:
+		b, err = hex.DecodeString(value)
+	default:
+		return nil, false, fmt.Errorf("%w: unknown encoding '%s'", ErrInvalidConfiguration, encoding)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return b, true, nil
+}
+
+// isByteSlice returns whether the type is a []byte.
+func isByteSlice(typ reflect.Type) bool {
+	return typ.PkgPath() == "" && typ.Kind() == reflect.Slice && typ.Elem().Kind() == reflect.Uint8
+}
@@ -0,0 +1,68 @@
+package inreq
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/rrgmc/inreq/resolver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeBytesEncoding(t *testing.T) {
+	type DataType struct {
+		Std    []byte  `inreq:"query,encoding=base64"`
+		Raw    []byte  `inreq:"query,encoding=base64"`
+		URL    []byte  `inreq:"query,encoding=base64url"`
+		Hex    []byte  `inreq:"header,name=X-Hash,encoding=hex"`
+		Ptr    *[]byte `inreq:"query,encoding=hex"`
+		Client netip.Addr
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?std=aGk%2B&raw=aGk&url=aGk-&ptr=ff00", nil)
+	r.Header.Set("X-Hash", "0a0b")
+	r.Header.Set("X-Client", "10.0.0.1")
+
+	data, err := DecodeType[DataType](r,
+		WithResolver(resolver.NewResolver(resolver.WithStdlibResolvers())),
+		WithMapTags(map[string]any{
+			"Client": "header,name=X-Client",
+		}))
+	require.NoError(t, err)
+	require.Equal(t, []byte("hi>"), data.Std)
+	require.Equal(t, []byte("hi"), data.Raw)
+	require.Equal(t, []byte("hi>"), data.URL)
+	require.Equal(t, []byte{0x0a, 0x0b}, data.Hex)
+	require.Equal(t, []byte{0xff, 0x00}, *data.Ptr)
+	require.Equal(t, netip.MustParseAddr("10.0.0.1"), data.Client)
+}
+
+func TestDecodeBytesEncodingError(t *testing.T) {
+	type DataType struct {
+		Hex []byte `inreq:"query,encoding=hex"`
+		URL []byte `inreq:"query,encoding=base64url"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?hex=xyz&url=a%2Bb", nil)
+
+	err := Decode(r, &DataType{}, WithCollectErrors(true))
+	var derrs DecodeErrors
+	require.ErrorAs(t, err, &derrs)
+	require.Len(t, derrs, 2)
+	require.ErrorIs(t, derrs[0], ErrCoerceInvalid)
+	require.ErrorIs(t, derrs[1], ErrCoerceInvalid)
+}
+
+func TestDecodeBytesEncodingConfigurationError(t *testing.T) {
+	type DataType struct {
+		Unknown []byte `inreq:"query,encoding=base32"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?unknown=aa", nil)
+
+	err := Decode(r, &DataType{}, WithCollectErrors(true))
+	require.ErrorIs(t, err, ErrInvalidConfiguration)
+	require.False(t, errors.As(err, new(CoerceError)))
+}
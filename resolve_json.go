@@ -0,0 +1,54 @@
+package inreq
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// Values of the "encoding" tag option for JSON-encoded values, which can be used with any field type.
+const (
+	EncodingJSON       = "json"
+	EncodingBase64JSON = "base64json" // base64 (standard or URL-safe, padding is optional) encoded JSON.
+)
+
+// convertJSONValue unmarshals JSON-encoded values using the "encoding" tag option.
+func convertJSONValue(tag *Tag, typ reflect.Type, value string) (any, bool, error) {
+	if !isJSONEncoding(tag) {
+		return nil, false, nil
+	}
+
+	data := []byte(value)
+	if tag.Options.Value("encoding", "") == EncodingBase64JSON {
+		var err error
+		data, err = decodeBase64Any(value)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	target := reflect.New(typ)
+	if err := json.Unmarshal(data, target.Interface()); err != nil {
+		return nil, false, err
+	}
+	return target.Elem().Interface(), true, nil
+}
+
+// isJSONEncoding returns whether the "encoding" tag option is set to a JSON encoding.
+func isJSONEncoding(tag *Tag) bool {
+	switch tag.Options.Value("encoding", "") {
+	case EncodingJSON, EncodingBase64JSON:
+		return true
+	}
+	return false
+}
+
+// decodeBase64Any decodes standard or URL-safe base64, with optional padding.
+func decodeBase64Any(value string) ([]byte, error) {
+	value = strings.TrimRight(value, "=")
+	if strings.ContainsAny(value, "-_") {
+		return base64.RawURLEncoding.DecodeString(value)
+	}
+	return base64.RawStdEncoding.DecodeString(value)
+}
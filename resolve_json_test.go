@@ -0,0 +1,66 @@
+package inreq
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeJSONEncoding(t *testing.T) {
+	type Filter struct {
+		Status []string `json:"status"`
+		Limit  int      `json:"limit"`
+	}
+	type Context struct {
+		TenantID string `json:"tenant_id"`
+	}
+	type DataType struct {
+		Filter  Filter            `inreq:"query,encoding=json"`
+		Tags    map[string]string `inreq:"query,encoding=json"`
+		IDs     []int             `inreq:"query,encoding=json"`
+		Context *Context          `inreq:"header,name=X-Context,encoding=base64json"`
+		Form    []Filter          `inreq:"form,encoding=json"`
+	}
+
+	query := url.Values{
+		"filter": {`{"status":["a","b"],"limit":10}`},
+		"tags":   {`{"x":"1"}`},
+		"ids":    {`[1,2,3]`},
+	}
+	r := httptest.NewRequest(http.MethodGet, "/?"+query.Encode(), nil)
+	r.Header.Set("X-Context", base64.RawURLEncoding.EncodeToString([]byte(`{"tenant_id":"t1"}`)))
+	r.Form = url.Values{"form": {`[{"limit":5}]`}}
+
+	data, err := DecodeType[DataType](r)
+	require.NoError(t, err)
+	require.Equal(t, Filter{Status: []string{"a", "b"}, Limit: 10}, data.Filter)
+	require.Equal(t, map[string]string{"x": "1"}, data.Tags)
+	require.Equal(t, []int{1, 2, 3}, data.IDs)
+	require.Equal(t, &Context{TenantID: "t1"}, data.Context)
+	require.Equal(t, []Filter{{Limit: 5}}, data.Form)
+}
+
+func TestDecodeJSONEncodingError(t *testing.T) {
+	type DataType struct {
+		Filter  map[string]any `inreq:"query,encoding=json"`
+		IDs     []int          `inreq:"query,encoding=json"`
+		Context map[string]any `inreq:"header,name=X-Context,encoding=base64json"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?filter=%7Binvalid&ids=[1]&ids=[2]", nil)
+	r.Header.Set("X-Context", "!!")
+
+	err := Decode(r, &DataType{}, WithCollectErrors(true))
+	var derrs DecodeErrors
+	require.ErrorAs(t, err, &derrs)
+	require.Len(t, derrs, 3)
+	for i, name := range []string{"filter", "ids", "X-Context"} {
+		require.Equal(t, name, derrs[i].TagName)
+		require.ErrorIs(t, derrs[i], ErrCoerceInvalid)
+	}
+	require.Equal(t, "{invalid", derrs[0].Value)
+}
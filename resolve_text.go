@@ -0,0 +1,37 @@
+package inreq
+
+import (
+	"encoding"
+	"reflect"
+)
+
+// ValueSetter is implemented by types which can be set from a string, like [flag.Value].
+type ValueSetter interface {
+	Set(value string) error
+}
+
+var valueSetterType = reflect.TypeOf(new(ValueSetter)).Elem()
+
+// convertTextValue decodes types implementing [encoding.TextUnmarshaler] or ValueSetter.
+// time.Time values are left to the Resolver, which may use a custom layout.
+func convertTextValue(tag *Tag, typ reflect.Type, value string) (any, bool, error) {
+	if typ == timeType {
+		return nil, false, nil
+	}
+	ptrType := reflect.PointerTo(typ)
+	switch {
+	case ptrType.Implements(textUnmarshalerType):
+		target := reflect.New(typ)
+		if err := target.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value)); err != nil {
+			return nil, false, err
+		}
+		return target.Elem().Interface(), true, nil
+	case ptrType.Implements(valueSetterType):
+		target := reflect.New(typ)
+		if err := target.Interface().(ValueSetter).Set(value); err != nil {
+			return nil, false, err
+		}
+		return target.Elem().Interface(), true, nil
+	}
+	return nil, false, nil
+}
@@ -0,0 +1,106 @@
+package inreq
+
+import (
+	"errors"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testTextLevel int
+
+func (l *testTextLevel) UnmarshalText(text []byte) error {
+	switch string(text) {
+	case "low":
+		*l = 1
+	case "high":
+		*l = 2
+	default:
+		return errors.New("invalid level")
+	}
+	return nil
+}
+
+type testSetterList struct {
+	items []string
+}
+
+func (l *testSetterList) Set(value string) error {
+	l.items = strings.Split(value, ":")
+	return nil
+}
+
+func TestDecodeTextUnmarshaler(t *testing.T) {
+	type DataType struct {
+		Level   testTextLevel    `inreq:"query"`
+		Levels  []testTextLevel  `inreq:"query,name=lv"`
+		Ptr     *testTextLevel   `inreq:"header,name=X-Level"`
+		PtrList []*testTextLevel `inreq:"form,name=fl"`
+		Path    testTextLevel    `inreq:"path"`
+		List    testSetterList   `inreq:"query"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?level=low&lv=low&lv=high&list=a:b", nil)
+	r.Header.Set("X-Level", "high")
+	r.Form = map[string][]string{"fl": {"high", "low"}}
+
+	high, low := testTextLevel(2), testTextLevel(1)
+
+	data, err := DecodeType[DataType](r, WithPathValue(PathValueFunc(func(r *http.Request, name string) (bool, any, error) {
+		return true, "high", nil
+	})))
+	require.NoError(t, err)
+	require.Equal(t, testTextLevel(1), data.Level)
+	require.Equal(t, []testTextLevel{1, 2}, data.Levels)
+	require.Equal(t, &high, data.Ptr)
+	require.Equal(t, []*testTextLevel{&high, &low}, data.PtrList)
+	require.Equal(t, testTextLevel(2), data.Path)
+	require.Equal(t, []string{"a", "b"}, data.List.items)
+}
+
+func TestDecodeTextUnmarshalerError(t *testing.T) {
+	type DataType struct {
+		Level testTextLevel `inreq:"query"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?level=medium", nil)
+
+	err := Decode(r, &DataType{})
+	var ferr FieldError
+	require.ErrorAs(t, err, &ferr)
+	require.Equal(t, "Level", ferr.FieldName)
+	require.Equal(t, "medium", ferr.Value)
+	require.ErrorIs(t, err, ErrCoerceInvalid)
+}
+
+func TestDecodeTextUnmarshalerStdlib(t *testing.T) {
+	type DataType struct {
+		Addr   netip.Addr     `inreq:"query"`
+		Prefix netip.Prefix   `inreq:"query"`
+		Port   netip.AddrPort `inreq:"query"`
+		IP     net.IP         `inreq:"header,name=X-IP"`
+		Addrs  []netip.Addr   `inreq:"query,name=addr"`
+		Int    *big.Int       `inreq:"query"`
+		Float  big.Float      `inreq:"query"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?addr=10.0.0.1&prefix=10.0.0.0/8&port=10.0.0.1:80"+
+		"&int=123456789012345678901234567890&float=1.5", nil)
+	r.Header.Set("X-IP", "::1")
+
+	data, err := DecodeType[DataType](r)
+	require.NoError(t, err)
+	require.Equal(t, netip.MustParseAddr("10.0.0.1"), data.Addr)
+	require.Equal(t, netip.MustParsePrefix("10.0.0.0/8"), data.Prefix)
+	require.Equal(t, netip.MustParseAddrPort("10.0.0.1:80"), data.Port)
+	require.Equal(t, net.ParseIP("::1"), data.IP)
+	require.Equal(t, []netip.Addr{netip.MustParseAddr("10.0.0.1")}, data.Addrs)
+	require.Equal(t, "123456789012345678901234567890", data.Int.String())
+	require.Zero(t, big.NewFloat(1.5).Cmp(&data.Float))
+}
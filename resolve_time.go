@@ -0,0 +1,174 @@
+package inreq
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Named time layouts for the "layout" tag option. Any other value is used as a [time.Parse] layout, which cannot
+// contain commas as they separate the tag options.
+const (
+	LayoutRFC3339   = "rfc3339"   // time.RFC3339, the default for the "tz" tag option.
+	LayoutDate      = "date"      // "2006-01-02"
+	LayoutDateTime  = "datetime"  // "2006-01-02 15:04:05"
+	LayoutUnix      = "unix"      // seconds since the Unix epoch, optionally with a fraction.
+	LayoutUnixMilli = "unixmilli" // milliseconds since the Unix epoch.
+	LayoutHTTPDate  = "httpdate"  // HTTP date format, using [http.ParseTime]. The default for date headers.
+)
+
+var (
+	timeType         = reflect.TypeOf(time.Time{})
+	timeLocationType = reflect.TypeOf(time.Location{})
+	timeLocations    sync.Map // cache of the loaded locations.
+)
+
+// durationUnits are the values of the "unit" tag option for time.Duration.
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// convertTimeValue parses time.Time values using the "layout" and "tz" tag options, or the HTTP date layout for
+// date headers. Other values are left to the Resolver.
+func convertTimeValue(tag *Tag, typ reflect.Type, value string) (any, bool, error) {
+	if typ != timeType {
+		return nil, false, nil
+	}
+	if !tag.Options.Exists("layout") && !tag.Options.Exists("tz") && defaultTimeLayout(tag) != LayoutHTTPDate {
+		return nil, false, nil
+	}
+
+	loc := time.UTC
+	if tz, ok := tag.Options.Get("tz"); ok {
+		var err error
+		if loc, err = timeLocation(tz); err != nil {
+			return nil, false, fmt.Errorf("%w: invalid time zone '%s': %w", ErrInvalidConfiguration, tz, err)
+		}
+	}
+
+	layout := tag.Options.Value("layout", defaultTimeLayout(tag))
+	var t time.Time
+	var err error
+	switch layout {
+	case LayoutRFC3339:
+		t, err = time.ParseInLocation(time.RFC3339, value, loc)
+	case LayoutDate:
+		t, err = time.ParseInLocation(time.DateOnly, value, loc)
+	case LayoutDateTime:
+		t, err = time.ParseInLocation(time.DateTime, value, loc)
+	case LayoutUnix:
+		t, err = parseUnixTime(value, time.Second)
+		t = t.In(loc)
+	case LayoutUnixMilli:
+		t, err = parseUnixTime(value, time.Millisecond)
+		t = t.In(loc)
+	case LayoutHTTPDate:
+		t, err = http.ParseTime(value)
+		t = t.In(loc)
+	default:
+		t, err = time.ParseInLocation(layout, value, loc)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return t, true, nil
+}
+
+// convertDurationValue parses time.Duration values using [time.ParseDuration] or as an integer number of
+// nanoseconds, or as a number of the "unit" tag option if set.
+func convertDurationValue(tag *Tag, typ reflect.Type, value string) (any, bool, error) {
+	if typ != durationType {
+		return nil, false, nil
+	}
+
+	unitName, ok := tag.Options.Get("unit")
+	if !ok {
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			// plain integers are nanoseconds, like the time.Duration type.
+			return time.Duration(i), true, nil
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, false, err
+		}
+		return d, true, nil
+	}
+
+	unit, ok := durationUnits[unitName]
+	if !ok {
+		return nil, false, fmt.Errorf("%w: invalid duration unit '%s'", ErrInvalidConfiguration, unitName)
+	}
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if i > math.MaxInt64/int64(unit) || i < math.MinInt64/int64(unit) {
+			return nil, false, ErrCoerceOverflow
+		}
+		return time.Duration(i) * unit, true, nil
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, false, err
+	}
+	d := f * float64(unit)
+	if math.IsNaN(d) || d > math.MaxInt64 || d < math.MinInt64 {
+		return nil, false, ErrCoerceOverflow
+	}
+	return time.Duration(d), true, nil
+}
+
+// parseUnixTime parses an Unix timestamp, optionally with a fraction.
+func parseUnixTime(value string, unit time.Duration) (time.Time, error) {
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if unit == time.Millisecond {
+			return time.UnixMilli(i), nil
+		}
+		return time.Unix(i, 0), nil
+	}
+	if !strings.Contains(value, ".") {
+		return time.Time{}, errors.New("invalid unix timestamp")
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	ns := f * float64(unit)
+	if math.IsNaN(ns) || ns > math.MaxInt64 || ns < math.MinInt64 {
+		return time.Time{}, ErrCoerceOverflow
+	}
+	return time.Unix(0, int64(ns)), nil
+}
+
+// convertTimeLocationValue loads *time.Location values using [time.LoadLocation]. Locations can't be copied, so
+// only pointer fields are supported.
+func convertTimeLocationValue(tag *Tag, typ reflect.Type, value string) (any, bool, error) {
+	if typ != timeLocationType {
+		return nil, false, nil
+	}
+	loc, err := timeLocation(value)
+	if err != nil {
+		return nil, false, err
+	}
+	return loc, true, nil
+}
+
+func timeLocation(name string) (*time.Location, error) {
+	if loc, ok := timeLocations.Load(name); ok {
+		return loc.(*time.Location), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, err
+	}
+	timeLocations.Store(name, loc)
+	return loc, nil
+}
@@ -0,0 +1,143 @@
+package inreq
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rrgmc/inreq/resolver"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeTime(t *testing.T) {
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	require.NoError(t, err)
+
+	type DataType struct {
+		Created   time.Time     `inreq:"query"`
+		Since     time.Time     `inreq:"query,layout=unix"`
+		SinceMs   *time.Time    `inreq:"query,layout=unixmilli"`
+		Date      time.Time     `inreq:"query,layout=date"`
+		LocalDate time.Time     `inreq:"query,layout=date,tz=America/Sao_Paulo"`
+		Custom    time.Time     `inreq:"query,layout=02/01/2006"`
+		Modified  time.Time     `inreq:"header,name=If-Modified-Since,layout=httpdate"`
+		Dates     []time.Time   `inreq:"query,name=dates,layout=date"`
+		Timeout   time.Duration `inreq:"query"`
+		TTL       time.Duration `inreq:"query,unit=s"`
+		Delay     time.Duration `inreq:"form,unit=ms"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?created=2024-01-31T10:20:30.5Z&since=1700000000"+
+		"&sincems=1700000000123&date=2024-01-31&localdate=2024-01-31&custom=31/01/2024"+
+		"&dates=2024-01-30&dates=2024-01-31&timeout=1m30s&ttl=30", nil)
+	r.Header.Set("If-Modified-Since", "Wed, 31 Jan 2024 10:20:30 GMT")
+	r.Form = map[string][]string{"delay": {"1.5"}}
+
+	data, err := DecodeType[DataType](r)
+	require.NoError(t, err)
+
+	require.Equal(t, time.Date(2024, 1, 31, 10, 20, 30, 500000000, time.UTC), data.Created)
+	require.Equal(t, time.Unix(1700000000, 0).UTC(), data.Since)
+	require.Equal(t, time.UnixMilli(1700000000123).UTC(), *data.SinceMs)
+	require.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), data.Date)
+	require.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, saoPaulo), data.LocalDate)
+	require.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), data.Custom)
+	require.Equal(t, time.Date(2024, 1, 31, 10, 20, 30, 0, time.UTC), data.Modified)
+	require.Equal(t, []time.Time{
+		time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC),
+		time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+	}, data.Dates)
+	require.Equal(t, 90*time.Second, data.Timeout)
+	require.Equal(t, 30*time.Second, data.TTL)
+	require.Equal(t, 1500*time.Microsecond, data.Delay)
+}
+
+func TestDecodeTimeError(t *testing.T) {
+	type DataType struct {
+		Date    time.Time     `inreq:"query,layout=date"`
+		Since   time.Time     `inreq:"query,layout=unix"`
+		Timeout time.Duration `inreq:"query"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?date=2024-31-01&since=x&timeout=30x", nil)
+
+	err := Decode(r, &DataType{}, WithCollectErrors(true))
+	var derrs DecodeErrors
+	require.ErrorAs(t, err, &derrs)
+	require.Len(t, derrs, 3)
+	for _, ferr := range derrs {
+		var cerr CoerceError
+		require.ErrorAs(t, ferr, &cerr)
+		require.ErrorIs(t, ferr, ErrCoerceInvalid)
+	}
+}
+
+func TestDecodeTimeConfigurationError(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		data any
+	}{
+		{
+			name: "tz",
+			data: &struct {
+				Zone time.Time `inreq:"query,tz=Invalid/Zone"`
+			}{},
+		},
+		{
+			name: "unit",
+			data: &struct {
+				TTL time.Duration `inreq:"query,unit=days"`
+			}{},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/?zone=2024-01-31T10:20:30Z&ttl=1", nil)
+
+			err := Decode(r, test.data, WithCollectErrors(true))
+			require.ErrorIs(t, err, ErrInvalidConfiguration)
+			var cerr CoerceError
+			require.False(t, errors.As(err, &cerr))
+			var derrs DecodeErrors
+			require.False(t, errors.As(err, &derrs))
+		})
+	}
+}
+
+func TestDecodeTimeLocation(t *testing.T) {
+	type DataType struct {
+		Zone  *time.Location `inreq:"query"`
+		Other *time.Location `inreq:"query"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?zone=America/Sao_Paulo&other=UTC", nil)
+
+	data, err := DecodeType[DataType](r)
+	require.NoError(t, err)
+	require.Equal(t, "America/Sao_Paulo", data.Zone.String())
+	require.Same(t, time.UTC, data.Other)
+
+	r = httptest.NewRequest(http.MethodGet, "/?zone=Invalid/Zone&other=UTC", nil)
+
+	err = Decode(r, &DataType{})
+	require.ErrorIs(t, err, ErrCoerceInvalid)
+}
+
+func TestDecodeTimeResolver(t *testing.T) {
+	type DataType struct {
+		Date     time.Time `inreq:"query"`
+		Modified time.Time `inreq:"header,name=If-Modified-Since"`
+		Custom   time.Time `inreq:"query,layout=02/01/2006"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/?date=2024-01-31&custom=30/01/2024", nil)
+	r.Header.Set("If-Modified-Since", "Wed, 31 Jan 2024 10:20:30 GMT")
+
+	data, err := DecodeType[DataType](r, WithResolver(resolver.NewResolver(resolver.WithValueResolver(
+		resolver.NewDefaultValueResolver(resolver.WithCustomTypes(resolver.NewValueResolverTime("2006-01-02")))))))
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), data.Date)
+	require.Equal(t, time.Date(2024, 1, 31, 10, 20, 30, 0, time.UTC), data.Modified)
+	require.Equal(t, time.Date(2024, 1, 30, 0, 0, 0, 0, time.UTC), data.Custom)
+}
@@ -6,3 +6,26 @@ import (
 
 // Resolver is the default Resolver.
 type Resolver = resolver.Resolver
+
+// Option is an option for NewResolver.
+type Option = resolver.Option
+
+// NewResolver creates a new default Resolver.
+// If not ValueResolver was set, a default one that supports only primitive types is used.
+func NewResolver(options ...Option) *Resolver {
+	return resolver.NewResolver(options...)
+}
+
+// WithValueResolver sets a custom ValueResolver to be used instead of the default.
+func WithValueResolver(valueResolver ValueResolver) Option {
+	return resolver.WithValueResolver(valueResolver)
+}
+
+// WithStdlibResolvers sets a DefaultValueResolver which supports common standard library types.
+// See WithStdlibCustomTypes for the list of types.
+// Custom value resolvers must be passed as parameters instead of using WithValueResolver, which replaces them.
+// They are tried first, in order, falling back to the next one for types they don't handle.
+func WithStdlibResolvers(valueResolvers ...ValueResolver) Option {
+	chain := append(chainValueResolver{}, valueResolvers...)
+	return WithValueResolver(append(chain, NewDefaultValueResolver(WithStdlibCustomTypes())))
+}
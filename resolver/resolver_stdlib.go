@@ -0,0 +1,94 @@
+package resolver
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"time"
+
+	"github.com/rrgmc/instruct/coerce"
+	"github.com/rrgmc/instruct/types"
+)
+
+// WithStdlibCustomTypes adds custom types for common standard library types which can't be decoded from strings
+// otherwise: [url.URL] and [mail.Address], and [time.Time] using [time.RFC3339] like the inreq default Resolver.
+// Pointers to them are also supported.
+// Types implementing [encoding.TextUnmarshaler], like [netip.Addr], [netip.Prefix], [netip.AddrPort], [net.IP],
+// [big.Int] and [big.Float], and *[time.Location] fields, are decoded by inreq before the Resolver is called.
+// Byte slices are decoded by the "encoding" tag option, which isn't available to resolvers.
+func WithStdlibCustomTypes() ValueOption {
+	return WithCustomTypes(StdlibTypeValueResolvers()...)
+}
+
+// StdlibTypeValueResolvers returns the list of TypeValueResolver used by WithStdlibCustomTypes.
+func StdlibTypeValueResolvers() []TypeValueResolver {
+	return []TypeValueResolver{
+		&ValueResolverURL{},
+		&ValueResolverMailAddress{},
+		NewValueResolverTime(time.RFC3339),
+	}
+}
+
+// ValueResolverURL resolves [url.URL] values using [url.Parse].
+type ValueResolverURL struct {
+}
+
+func (d *ValueResolverURL) ResolveTypeValue(target reflect.Value, value any) error {
+	if target.Type() == urlType {
+		return resolveStdlibString(target, value, url.Parse)
+	}
+	return types.ErrCoerceUnknown
+}
+
+// ValueResolverMailAddress resolves [mail.Address] values using [mail.ParseAddress].
+type ValueResolverMailAddress struct {
+}
+
+func (d *ValueResolverMailAddress) ResolveTypeValue(target reflect.Value, value any) error {
+	if target.Type() == mailAddressType {
+		return resolveStdlibString(target, value, mail.ParseAddress)
+	}
+	return types.ErrCoerceUnknown
+}
+
+var (
+	urlType         = reflect.TypeOf(url.URL{})
+	mailAddressType = reflect.TypeOf(mail.Address{})
+)
+
+// resolveStdlibString parses a string value, and sets the value pointed to by the result on the target.
+func resolveStdlibString[T any](target reflect.Value, value any, parse func(string) (*T, error)) error {
+	switch v := value.(type) {
+	case T:
+		target.Set(reflect.ValueOf(v))
+		return nil
+	case *T:
+		target.Set(reflect.ValueOf(v).Elem())
+		return nil
+	}
+	s, err := coerce.String(value)
+	if err != nil {
+		return err
+	}
+	v, err := parse(s)
+	if err != nil {
+		return fmt.Errorf("%w: %w", types.ErrCoerceInvalid, err)
+	}
+	target.Set(reflect.ValueOf(v).Elem())
+	return nil
+}
+
+// chainValueResolver tries each ValueResolver in order, until one of them handles the type.
+type chainValueResolver []ValueResolver
+
+func (c chainValueResolver) ResolveValue(target reflect.Value, value any) error {
+	var err error
+	for _, r := range c {
+		if err = r.ResolveValue(target, value); !errors.Is(err, types.ErrCoerceUnknown) {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,83 @@
+package resolver
+
+import (
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"testing"
+
+	"github.com/rrgmc/instruct/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStdlibResolvers(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  any
+		value   any
+		want    any
+		wantErr bool
+	}{
+		{name: "url.URL", target: new(url.URL), value: "https://example.com/a?b=1", want: url.URL{
+			Scheme: "https", Host: "example.com", Path: "/a", RawQuery: "b=1",
+		}},
+		{name: "url.URL pointer", target: new(*url.URL), value: "https://example.com/a?b=1", want: &url.URL{
+			Scheme: "https", Host: "example.com", Path: "/a", RawQuery: "b=1",
+		}},
+		{name: "mail.Address", target: new(mail.Address), value: "John <john@example.com>",
+			want: mail.Address{Name: "John", Address: "john@example.com"}},
+		{name: "mail.Address slice", target: new([]mail.Address), value: []string{"a@example.com", "b@example.com"},
+			want: []mail.Address{{Address: "a@example.com"}, {Address: "b@example.com"}}},
+		{name: "int", target: new(int), value: "12", want: 12},
+		{name: "url.URL error", target: new(url.URL), value: ":", wantErr: true},
+		{name: "mail.Address error", target: new(mail.Address), value: "john", wantErr: true},
+	}
+
+	r := NewResolver(WithStdlibResolvers())
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := reflect.ValueOf(tt.target).Elem()
+			err := r.Resolve(target, tt.value)
+			if tt.wantErr {
+				require.ErrorIs(t, err, types.ErrCoerceInvalid)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, target.Interface())
+		})
+	}
+}
+
+func TestStdlibResolversChain(t *testing.T) {
+	r := NewResolver(WithStdlibResolvers(NewDefaultValueResolver(WithCustomTypes(&testValueResolverNetIP{}))))
+
+	var addr netip.Addr
+	require.NoError(t, r.Resolve(reflect.ValueOf(&addr).Elem(), []byte{10, 0, 0, 1}))
+	require.Equal(t, netip.MustParseAddr("10.0.0.1"), addr)
+
+	var u url.URL
+	require.NoError(t, r.Resolve(reflect.ValueOf(&u).Elem(), "https://example.com"))
+	require.Equal(t, "example.com", u.Host)
+
+	var i int
+	require.NoError(t, r.Resolve(reflect.ValueOf(&i).Elem(), "12"))
+	require.Equal(t, 12, i)
+}
+
+// testValueResolverNetIP resolves netip.Addr values from bytes.
+type testValueResolverNetIP struct {
+}
+
+func (d *testValueResolverNetIP) ResolveTypeValue(target reflect.Value, value any) error {
+	if b, ok := value.([]byte); ok && target.Type() == reflect.TypeOf(netip.Addr{}) {
+		addr, ok := netip.AddrFromSlice(b)
+		if !ok {
+			return types.ErrCoerceInvalid
+		}
+		target.Set(reflect.ValueOf(addr))
+		return nil
+	}
+	return types.ErrCoerceUnknown
+}
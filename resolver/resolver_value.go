@@ -15,3 +15,32 @@ type TypeValueResolver = resolver.TypeValueResolver
 // TypeValueResolverReflect is a custom type handler for a ValueResolver.
 // It SHOULD process value using reflection.
 type TypeValueResolverReflect = resolver.TypeValueResolverReflect
+
+// DefaultValueResolver resolves primitive types and the custom types added to it.
+type DefaultValueResolver = resolver.DefaultValueResolver
+
+// ValueOption is an option for NewDefaultValueResolver.
+type ValueOption = resolver.ValueOption
+
+// NewDefaultValueResolver creates a new DefaultValueResolver.
+func NewDefaultValueResolver(options ...ValueOption) *DefaultValueResolver {
+	return resolver.NewDefaultValueResolver(options...)
+}
+
+// WithCustomTypes adds custom types.
+func WithCustomTypes(customTypes ...TypeValueResolver) ValueOption {
+	return resolver.WithCustomTypes(customTypes...)
+}
+
+// WithCustomTypesReflect adds custom types that uses reflection.
+func WithCustomTypesReflect(customTypes ...TypeValueResolverReflect) ValueOption {
+	return resolver.WithCustomTypesReflect(customTypes...)
+}
+
+// ValueResolverTime resolves time.Time values using a layout.
+type ValueResolverTime = resolver.ValueResolverTime
+
+// NewValueResolverTime creates a ValueResolverTime using the [time.Parse] layout.
+func NewValueResolverTime(layout string) *ValueResolverTime {
+	return resolver.NewValueResolverTime(layout)
+}
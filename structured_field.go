@@ -0,0 +1,596 @@
+package inreq
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/rrgmc/instruct/types"
+)
+
+// Values of the "sf" tag option, to parse header values as RFC 8941 Structured Fields.
+const (
+	SFTypeItem       = "item"
+	SFTypeList       = "list"
+	SFTypeDictionary = "dictionary"
+)
+
+// SFToken is a Structured Field token, like "gzip" or "text/html".
+type SFToken string
+
+// SFItem is a Structured Field item, or an inner list, with its parameters.
+type SFItem struct {
+	// Value is a bool, int64, float64 (decimal), string, SFToken, []byte (byte sequence), or []SFItem for inner
+	// lists.
+	Value  any
+	Params SFParams
+}
+
+// SFParam is a Structured Field parameter. Parameters without a value have the value true.
+type SFParam struct {
+	Name  string
+	Value any
+}
+
+// SFParams is the list of parameters of a Structured Field item, in order.
+type SFParams []SFParam
+
+// Get returns the value of the parameter.
+func (p SFParams) Get(name string) (any, bool) {
+	for _, param := range p {
+		if param.Name == name {
+			return param.Value, true
+		}
+	}
+	return nil, false
+}
+
+// SFList is a Structured Field list.
+type SFList []SFItem
+
+// SFMember is a Structured Field dictionary member. Members without a value have the value true.
+type SFMember struct {
+	Name string
+	Item SFItem
+}
+
+// SFDictionary is a Structured Field dictionary, in order.
+type SFDictionary []SFMember
+
+// Get returns the member item.
+func (d SFDictionary) Get(name string) (SFItem, bool) {
+	for _, member := range d {
+		if member.Name == name {
+			return member.Item, true
+		}
+	}
+	return SFItem{}, false
+}
+
+var (
+	sfItemType       = reflect.TypeOf(SFItem{})
+	sfListType       = reflect.TypeOf(SFList{})
+	sfDictionaryType = reflect.TypeOf(SFDictionary{})
+	sfItemsType      = reflect.TypeOf([]SFItem{})
+)
+
+// decodeStructuredField parses the value as a Structured Field of the sfType kind, returning a value of the
+// target type. Dictionaries can be decoded into structs, with the member names mapped by the FieldNameMapper.
+func decodeStructuredField(ctx DecodeContext, sfType string, typ reflect.Type, value string) (any, error) {
+	target := reflect.New(typ).Elem()
+	p := &sfParser{s: value}
+
+	var err error
+	switch sfType {
+	case SFTypeItem:
+		var item SFItem
+		if item, err = sfParse(p, p.parseItem); err == nil {
+			err = sfAssignItem(target, item)
+		}
+	case SFTypeList:
+		var list SFList
+		if list, err = sfParse(p, p.parseList); err == nil {
+			err = sfAssignList(target, list)
+		}
+	case SFTypeDictionary:
+		var dict SFDictionary
+		if dict, err = sfParse(p, p.parseDictionary); err == nil {
+			err = sfAssignDictionary(ctx, target, dict)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown structured field type '%s'", ErrInvalidConfiguration, sfType)
+	}
+	if err != nil {
+		if errors.Is(err, ErrInvalidConfiguration) {
+			return nil, err
+		}
+		return nil, types.NewCoerceError(err)
+	}
+	return target.Interface(), nil
+}
+
+func sfAssignItem(target reflect.Value, item SFItem) error {
+	if target.Kind() == reflect.Pointer {
+		target.Set(reflect.New(target.Type().Elem()))
+		target = target.Elem()
+	}
+	if target.Type() == sfItemType {
+		target.Set(reflect.ValueOf(item))
+		return nil
+	}
+	return sfAssignValue(target, item.Value)
+}
+
+func sfAssignList(target reflect.Value, list SFList) error {
+	if target.Kind() == reflect.Pointer {
+		target.Set(reflect.New(target.Type().Elem()))
+		target = target.Elem()
+	}
+	switch {
+	case target.Type() == sfListType:
+		target.Set(reflect.ValueOf(list))
+		return nil
+	case target.Kind() != reflect.Slice:
+		return fmt.Errorf("%w: unsupported structured field target type '%s' for list", ErrInvalidConfiguration,
+			target.Type())
+	}
+	slice := reflect.MakeSlice(target.Type(), len(list), len(list))
+	for i, item := range list {
+		if err := sfAssignItem(slice.Index(i), item); err != nil {
+			return err
+		}
+	}
+	target.Set(slice)
+	return nil
+}
+
+func sfAssignDictionary(ctx DecodeContext, target reflect.Value, dict SFDictionary) error {
+	if target.Kind() == reflect.Pointer {
+		target.Set(reflect.New(target.Type().Elem()))
+		target = target.Elem()
+	}
+	switch {
+	case target.Type() == sfDictionaryType:
+		target.Set(reflect.ValueOf(dict))
+		return nil
+	case target.Kind() == reflect.Map && target.Type().Key().Kind() == reflect.String:
+		m := reflect.MakeMapWithSize(target.Type(), len(dict))
+		for _, member := range dict {
+			v := reflect.New(target.Type().Elem()).Elem()
+			if err := sfAssignItem(v, member.Item); err != nil {
+				return fmt.Errorf("member '%s': %w", member.Name, err)
+			}
+			m.SetMapIndex(reflect.ValueOf(member.Name).Convert(target.Type().Key()), v)
+		}
+		target.Set(m)
+		return nil
+	case target.Kind() == reflect.Struct:
+		for i := 0; i < target.NumField(); i++ {
+			sf := target.Type().Field(i)
+			if !sf.IsExported() {
+				continue
+			}
+			name := ctx.FieldNameMapper()(OperationHeader, sf.Name)
+			item, ok := dict.Get(name)
+			if !ok {
+				continue
+			}
+			if err := sfAssignItem(target.Field(i), item); err != nil {
+				return fmt.Errorf("member '%s': %w", name, err)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: unsupported structured field target type '%s' for dictionary",
+		ErrInvalidConfiguration, target.Type())
+}
+
+// sfAssignValue sets a bare item value, or an inner list, into the target.
+func sfAssignValue(target reflect.Value, value any) error {
+	switch v := value.(type) {
+	case bool:
+		if target.Kind() == reflect.Bool {
+			target.SetBool(v)
+			return nil
+		}
+	case int64:
+		switch target.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			if target.OverflowInt(v) {
+				return ErrCoerceOverflow
+			}
+			target.SetInt(v)
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			if v < 0 || target.OverflowUint(uint64(v)) {
+				return ErrCoerceOverflow
+			}
+			target.SetUint(uint64(v))
+			return nil
+		case reflect.Float32, reflect.Float64:
+			target.SetFloat(float64(v))
+			return nil
+		}
+	case float64:
+		if target.Kind() == reflect.Float32 || target.Kind() == reflect.Float64 {
+			target.SetFloat(v)
+			return nil
+		}
+	case string:
+		if target.Kind() == reflect.String {
+			target.SetString(v)
+			return nil
+		}
+	case SFToken:
+		if target.Kind() == reflect.String {
+			target.SetString(string(v))
+			return nil
+		}
+	case []byte:
+		if target.Kind() == reflect.Slice && target.Type().Elem().Kind() == reflect.Uint8 {
+			target.SetBytes(v)
+			return nil
+		}
+	case []SFItem:
+		if target.Type() == sfItemsType || target.Type() == sfListType {
+			target.Set(reflect.ValueOf(v).Convert(target.Type()))
+			return nil
+		}
+		if target.Kind() == reflect.Slice {
+			slice := reflect.MakeSlice(target.Type(), len(v), len(v))
+			for i, item := range v {
+				if err := sfAssignItem(slice.Index(i), item); err != nil {
+					return err
+				}
+			}
+			target.Set(slice)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: cannot set structured field value of type '%T' into '%s'", ErrCoerceUnsupported,
+		value, target.Type())
+}
+
+// sfParser parses Structured Fields following the RFC 8941 parsing algorithms (section 4.2).
+type sfParser struct {
+	s   string
+	pos int
+}
+
+// sfParse runs the parse function on the whole input, discarding leading and trailing spaces.
+func sfParse[T any](p *sfParser, f func() (T, error)) (T, error) {
+	p.skipSP()
+	ret, err := f()
+	if err != nil {
+		return ret, err
+	}
+	p.skipSP()
+	if !p.eof() {
+		return ret, p.errorf("unexpected character '%c'", p.s[p.pos])
+	}
+	return ret, nil
+}
+
+func (p *sfParser) eof() bool {
+	return p.pos >= len(p.s)
+}
+
+func (p *sfParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *sfParser) skipSP() {
+	for p.peek() == ' ' {
+		p.pos++
+	}
+}
+
+func (p *sfParser) skipOWS() {
+	for p.peek() == ' ' || p.peek() == '\t' {
+		p.pos++
+	}
+}
+
+// errorf returns a parse error, which wraps ErrCoerceInvalid.
+func (p *sfParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w: invalid structured field at position %d: %s", ErrCoerceInvalid, p.pos,
+		fmt.Sprintf(format, args...))
+}
+
+func (p *sfParser) parseList() (SFList, error) {
+	var ret SFList
+	for !p.eof() {
+		item, err := p.parseItemOrInnerList()
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, item)
+		p.skipOWS()
+		if p.eof() {
+			return ret, nil
+		}
+		if p.peek() != ',' {
+			return nil, p.errorf("expected ','")
+		}
+		p.pos++
+		p.skipOWS()
+		if p.eof() {
+			return nil, p.errorf("trailing ','")
+		}
+	}
+	return ret, nil
+}
+
+func (p *sfParser) parseDictionary() (SFDictionary, error) {
+	var ret SFDictionary
+	for !p.eof() {
+		name, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		var item SFItem
+		if p.peek() == '=' {
+			p.pos++
+			if item, err = p.parseItemOrInnerList(); err != nil {
+				return nil, err
+			}
+		} else {
+			item.Value = true
+			if item.Params, err = p.parseParameters(); err != nil {
+				return nil, err
+			}
+		}
+		ret = sfDictionarySet(ret, name, item)
+		p.skipOWS()
+		if p.eof() {
+			return ret, nil
+		}
+		if p.peek() != ',' {
+			return nil, p.errorf("expected ','")
+		}
+		p.pos++
+		p.skipOWS()
+		if p.eof() {
+			return nil, p.errorf("trailing ','")
+		}
+	}
+	return ret, nil
+}
+
+// sfDictionarySet adds the member, overwriting the value of an existing member with the same name.
+func sfDictionarySet(dict SFDictionary, name string, item SFItem) SFDictionary {
+	for i := range dict {
+		if dict[i].Name == name {
+			dict[i].Item = item
+			return dict
+		}
+	}
+	return append(dict, SFMember{Name: name, Item: item})
+}
+
+func (p *sfParser) parseItemOrInnerList() (SFItem, error) {
+	if p.peek() == '(' {
+		return p.parseInnerList()
+	}
+	return p.parseItem()
+}
+
+func (p *sfParser) parseInnerList() (SFItem, error) {
+	p.pos++ // '('
+	items := []SFItem{}
+	for !p.eof() {
+		p.skipSP()
+		if p.peek() == ')' {
+			p.pos++
+			params, err := p.parseParameters()
+			if err != nil {
+				return SFItem{}, err
+			}
+			return SFItem{Value: items, Params: params}, nil
+		}
+		item, err := p.parseItem()
+		if err != nil {
+			return SFItem{}, err
+		}
+		items = append(items, item)
+		if c := p.peek(); c != ' ' && c != ')' {
+			return SFItem{}, p.errorf("expected ' ' or ')' in inner list")
+		}
+	}
+	return SFItem{}, p.errorf("unterminated inner list")
+}
+
+func (p *sfParser) parseItem() (SFItem, error) {
+	value, err := p.parseBareItem()
+	if err != nil {
+		return SFItem{}, err
+	}
+	params, err := p.parseParameters()
+	if err != nil {
+		return SFItem{}, err
+	}
+	return SFItem{Value: value, Params: params}, nil
+}
+
+func (p *sfParser) parseParameters() (SFParams, error) {
+	var ret SFParams
+	for p.peek() == ';' {
+		p.pos++
+		p.skipSP()
+		name, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		var value any = true
+		if p.peek() == '=' {
+			p.pos++
+			if value, err = p.parseBareItem(); err != nil {
+				return nil, err
+			}
+		}
+		ret = sfParamsSet(ret, name, value)
+	}
+	return ret, nil
+}
+
+// sfParamsSet adds the parameter, overwriting the value of an existing parameter with the same name.
+func sfParamsSet(params SFParams, name string, value any) SFParams {
+	for i := range params {
+		if params[i].Name == name {
+			params[i].Value = value
+			return params
+		}
+	}
+	return append(params, SFParam{Name: name, Value: value})
+}
+
+func (p *sfParser) parseKey() (string, error) {
+	if c := p.peek(); !isLCAlpha(c) && c != '*' {
+		return "", p.errorf("invalid key")
+	}
+	start := p.pos
+	for c := p.peek(); isLCAlpha(c) || isDigit(c) || strings.IndexByte("_-.*", c) >= 0; c = p.peek() {
+		p.pos++
+	}
+	return p.s[start:p.pos], nil
+}
+
+func (p *sfParser) parseBareItem() (any, error) {
+	switch c := p.peek(); {
+	case c == '-' || isDigit(c):
+		return p.parseNumber()
+	case c == '"':
+		return p.parseString()
+	case c == '*' || isAlpha(c):
+		return p.parseToken(), nil
+	case c == ':':
+		return p.parseByteSequence()
+	case c == '?':
+		return p.parseBoolean()
+	}
+	return nil, p.errorf("invalid item")
+}
+
+func (p *sfParser) parseNumber() (any, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	if !isDigit(p.peek()) {
+		return nil, p.errorf("invalid number")
+	}
+	digitsStart, dot := p.pos, -1
+	for c := p.peek(); isDigit(c) || (c == '.' && dot < 0); c = p.peek() {
+		if c == '.' {
+			if p.pos-digitsStart > 12 {
+				return nil, p.errorf("decimal integer part too long")
+			}
+			dot = p.pos
+		}
+		p.pos++
+		if dot < 0 && p.pos-digitsStart > 15 {
+			return nil, p.errorf("integer too long")
+		}
+		if dot >= 0 && p.pos-digitsStart > 16 {
+			return nil, p.errorf("decimal too long")
+		}
+	}
+
+	num := p.s[start:p.pos]
+	if dot < 0 {
+		return strconv.ParseInt(num, 10, 64)
+	}
+	if fraction := p.pos - dot - 1; fraction < 1 || fraction > 3 {
+		return nil, p.errorf("invalid decimal fraction")
+	}
+	f, err := strconv.ParseFloat(num, 64)
+	if err != nil || math.IsInf(f, 0) {
+		return nil, p.errorf("invalid decimal")
+	}
+	return f, nil
+}
+
+func (p *sfParser) parseString() (string, error) {
+	p.pos++ // '"'
+	var b strings.Builder
+	for !p.eof() {
+		c := p.s[p.pos]
+		p.pos++
+		switch {
+		case c == '\\':
+			if next := p.peek(); next != '"' && next != '\\' {
+				return "", p.errorf("invalid string escape")
+			}
+			b.WriteByte(p.s[p.pos])
+			p.pos++
+		case c == '"':
+			return b.String(), nil
+		case c < 0x20 || c > 0x7e:
+			return "", p.errorf("invalid string character")
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *sfParser) parseToken() SFToken {
+	start := p.pos
+	p.pos++
+	for c := p.peek(); isTChar(c) || c == ':' || c == '/'; c = p.peek() {
+		p.pos++
+	}
+	return SFToken(p.s[start:p.pos])
+}
+
+func (p *sfParser) parseByteSequence() ([]byte, error) {
+	p.pos++ // ':'
+	end := strings.IndexByte(p.s[p.pos:], ':')
+	if end < 0 {
+		return nil, p.errorf("unterminated byte sequence")
+	}
+	value := p.s[p.pos : p.pos+end]
+	p.pos += end + 1
+	b, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(value, "="))
+	if err != nil {
+		return nil, p.errorf("invalid byte sequence: %s", err)
+	}
+	return b, nil
+}
+
+func (p *sfParser) parseBoolean() (bool, error) {
+	p.pos++ // '?'
+	switch p.peek() {
+	case '1':
+		p.pos++
+		return true, nil
+	case '0':
+		p.pos++
+		return false, nil
+	}
+	return false, p.errorf("invalid boolean")
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLCAlpha(c byte) bool {
+	return c >= 'a' && c <= 'z'
+}
+
+func isAlpha(c byte) bool {
+	return isLCAlpha(c) || (c >= 'A' && c <= 'Z')
+}
+
+// isTChar returns whether the character is a valid RFC 9110 token character.
+func isTChar(c byte) bool {
+	return isDigit(c) || isAlpha(c) || (c != 0 && strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0)
+}
@@ -0,0 +1,232 @@
+package inreq
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStructuredField(t *testing.T) {
+	tests := []struct {
+		name    string
+		sfType  string
+		value   string
+		want    any
+		wantErr bool
+	}{
+		{
+			name:   "integer",
+			sfType: SFTypeItem,
+			value:  "-42",
+			want:   SFItem{Value: int64(-42)},
+		},
+		{
+			name:   "decimal with params",
+			sfType: SFTypeItem,
+			value:  "4.5;a=1;b;c=?0",
+			want: SFItem{Value: 4.5, Params: SFParams{
+				{Name: "a", Value: int64(1)},
+				{Name: "b", Value: true},
+				{Name: "c", Value: false},
+			}},
+		},
+		{
+			name:   "string",
+			sfType: SFTypeItem,
+			value:  ` "a \"b\" \\ c" `,
+			want:   SFItem{Value: `a "b" \ c`},
+		},
+		{
+			name:   "token",
+			sfType: SFTypeItem,
+			value:  "text/html",
+			want:   SFItem{Value: SFToken("text/html")},
+		},
+		{
+			name:   "byte sequence",
+			sfType: SFTypeItem,
+			value:  ":aGVsbG8=:",
+			want:   SFItem{Value: []byte("hello")},
+		},
+		{
+			name:   "list with inner list",
+			sfType: SFTypeList,
+			value:  `sugar, tea;x=1, ("a" b);lvl=5, ()`,
+			want: SFList{
+				{Value: SFToken("sugar")},
+				{Value: SFToken("tea"), Params: SFParams{{Name: "x", Value: int64(1)}}},
+				{Value: []SFItem{{Value: "a"}, {Value: SFToken("b")}}, Params: SFParams{{Name: "lvl", Value: int64(5)}}},
+				{Value: []SFItem{}},
+			},
+		},
+		{
+			name:   "dictionary",
+			sfType: SFTypeDictionary,
+			value:  "a=?0, b, c;foo=bar, a=1",
+			want: SFDictionary{
+				{Name: "a", Item: SFItem{Value: int64(1)}},
+				{Name: "b", Item: SFItem{Value: true}},
+				{Name: "c", Item: SFItem{Value: true, Params: SFParams{{Name: "foo", Value: SFToken("bar")}}}},
+			},
+		},
+		{
+			name:    "integer too long",
+			sfType:  SFTypeItem,
+			value:   "1234567890123456",
+			wantErr: true,
+		},
+		{
+			name:    "decimal fraction too long",
+			sfType:  SFTypeItem,
+			value:   "1.1234",
+			wantErr: true,
+		},
+		{
+			name:    "trailing comma",
+			sfType:  SFTypeList,
+			value:   "a, b,",
+			wantErr: true,
+		},
+		{
+			name:    "invalid key",
+			sfType:  SFTypeDictionary,
+			value:   "A=1",
+			wantErr: true,
+		},
+		{
+			name:    "unterminated string",
+			sfType:  SFTypeItem,
+			value:   `"abc`,
+			wantErr: true,
+		},
+		{
+			name:    "unterminated inner list",
+			sfType:  SFTypeList,
+			value:   `(a b`,
+			wantErr: true,
+		},
+		{
+			name:    "multiple items",
+			sfType:  SFTypeItem,
+			value:   `a, b`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got any
+			var err error
+			p := &sfParser{s: tt.value}
+			switch tt.sfType {
+			case SFTypeItem:
+				got, err = sfParse(p, p.parseItem)
+			case SFTypeList:
+				got, err = sfParse(p, p.parseList)
+			case SFTypeDictionary:
+				got, err = sfParse(p, p.parseDictionary)
+			}
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrCoerceInvalid)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDecodeHeaderStructuredField(t *testing.T) {
+	type Priority struct {
+		U int
+		I bool
+	}
+	type DataType struct {
+		Priority  Priority            `inreq:"header,sf=dictionary"`
+		Hints     map[string]SFItem   `inreq:"header,name=X-Client-Hints,sf=dictionary"`
+		Encodings []string            `inreq:"header,name=Accept-Encoding,sf=list"`
+		Status    SFList              `inreq:"header,name=Cache-Status,sf=list"`
+		Groups    [][]int             `inreq:"header,name=X-Groups,sf=list"`
+		Count     *int                `inreq:"header,name=X-Count,sf=item"`
+		Item      SFItem              `inreq:"header,name=X-Item,sf=item"`
+		Dict      SFDictionary        `inreq:"header,name=X-Dict,sf=dictionary"`
+		Limits    map[string]*float64 `inreq:"header,name=X-Limits,sf=dictionary"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Priority", "u=3, i")
+	r.Header.Set("X-Client-Hints", `model="x1";v=2`)
+	r.Header.Add("Accept-Encoding", "gzip")
+	r.Header.Add("Accept-Encoding", `br, "zstd"`)
+	r.Header.Set("Cache-Status", "ExampleCache; hit; ttl=376")
+	r.Header.Set("X-Groups", "(1 2), (3)")
+	r.Header.Set("X-Count", "7")
+	r.Header.Set("X-Item", ":AQI=:;k")
+	r.Header.Set("X-Dict", "a=1")
+	r.Header.Set("X-Limits", "cpu=1.5, mem=512")
+
+	data, err := DecodeType[DataType](r)
+	require.NoError(t, err)
+	require.Equal(t, Priority{U: 3, I: true}, data.Priority)
+	require.Equal(t, map[string]SFItem{
+		"model": {Value: "x1", Params: SFParams{{Name: "v", Value: int64(2)}}},
+	}, data.Hints)
+	require.Equal(t, []string{"gzip", "br", "zstd"}, data.Encodings)
+	require.Equal(t, SFList{{Value: SFToken("ExampleCache"), Params: SFParams{
+		{Name: "hit", Value: true},
+		{Name: "ttl", Value: int64(376)},
+	}}}, data.Status)
+	require.Equal(t, [][]int{{1, 2}, {3}}, data.Groups)
+	require.Equal(t, 7, *data.Count)
+	require.Equal(t, SFItem{Value: []byte{1, 2}, Params: SFParams{{Name: "k", Value: true}}}, data.Item)
+	require.Equal(t, SFDictionary{{Name: "a", Item: SFItem{Value: int64(1)}}}, data.Dict)
+	cpu, mem := 1.5, 512.0
+	require.Equal(t, map[string]*float64{"cpu": &cpu, "mem": &mem}, data.Limits)
+}
+
+func TestDecodeHeaderStructuredFieldError(t *testing.T) {
+	type DataType struct {
+		Invalid  int  `inreq:"header,name=X-Invalid,sf=item"`
+		Type     int  `inreq:"header,name=X-Type,sf=item"`
+		Overflow int8 `inreq:"header,name=X-Overflow,sf=item"`
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Invalid", "1.")
+	r.Header.Set("X-Type", `"text"`)
+	r.Header.Set("X-Overflow", "1000")
+
+	err := Decode(r, &DataType{}, WithCollectErrors(true))
+	var derrs DecodeErrors
+	require.ErrorAs(t, err, &derrs)
+	require.Len(t, derrs, 3)
+	require.ErrorIs(t, derrs[0], ErrCoerceInvalid)
+	require.ErrorIs(t, derrs[1], ErrCoerceUnsupported)
+	require.ErrorIs(t, derrs[2], ErrCoerceOverflow)
+
+	var coerceErr CoerceError
+	for _, derr := range derrs {
+		require.ErrorAs(t, derr, &coerceErr)
+	}
+}
+
+func TestDecodeHeaderStructuredFieldConfigurationError(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Target", "a=1")
+
+	for _, data := range []any{
+		&struct {
+			Target string `inreq:"header,name=X-Target,sf=dictionary"`
+		}{},
+		&struct {
+			Target string `inreq:"header,name=X-Target,sf=unknown"`
+		}{},
+	} {
+		err := Decode(r, data, WithCollectErrors(true))
+		require.ErrorIs(t, err, ErrInvalidConfiguration)
+		require.False(t, errors.As(err, new(CoerceError)))
+	}
+}
@@ -0,0 +1,106 @@
+package inreq
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// reflectValueElem returns the first non-pointer value from the [reflect.Value].
+func reflectValueElem(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Pointer && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+// fieldPath returns the path of the struct field inside root, using the unmodified struct field names, the same
+// format used by [RequiredError.FieldName]. If field is root itself, its type name is returned.
+func fieldPath(root reflect.Value, field reflect.Value) (string, bool) {
+	root = reflectValueElem(root)
+	if !root.IsValid() || !field.IsValid() || !field.CanAddr() {
+		return "", false
+	}
+	if root.CanAddr() {
+		if root.Type() == field.Type() && root.UnsafeAddr() == field.UnsafeAddr() {
+			return root.Type().String(), true
+		}
+		// fields stored inside the root value are found by their offset.
+		if addr := field.UnsafeAddr(); addr >= root.UnsafeAddr() && addr-root.UnsafeAddr() < root.Type().Size() {
+			key := fieldPathKey{offset: addr - root.UnsafeAddr(), typ: field.Type()}
+			if path, ok := typeFieldPaths(root.Type())[key]; ok {
+				return path, true
+			}
+		}
+	}
+	// fields inside pointers are found by walking the value.
+	path, ok := findFieldPath(root, field.UnsafeAddr(), field.Type(), nil)
+	if !ok {
+		return "", false
+	}
+	return strings.Join(path, "."), true
+}
+
+// fieldPaths caches the paths of the exported fields of each struct type, which are not inside pointers.
+var fieldPaths sync.Map // map[reflect.Type]map[fieldPathKey]string
+
+// fieldPathKey is a field by its offset inside the root struct and its type, as nested fields can have the same
+// offset of their parent.
+type fieldPathKey struct {
+	offset uintptr
+	typ    reflect.Type
+}
+
+// typeFieldPaths returns the paths of the exported fields of the type, using a cache.
+func typeFieldPaths(typ reflect.Type) map[fieldPathKey]string {
+	if paths, ok := fieldPaths.Load(typ); ok {
+		return paths.(map[fieldPathKey]string)
+	}
+	paths := map[fieldPathKey]string{}
+	if typ.Kind() == reflect.Struct {
+		addTypeFieldPaths(paths, typ, 0, nil)
+	}
+	fieldPaths.Store(typ, paths)
+	return paths
+}
+
+// addTypeFieldPaths adds the paths of the struct fields, in the same order findFieldPath looks for them.
+func addTypeFieldPaths(paths map[fieldPathKey]string, typ reflect.Type, offset uintptr, path []string) {
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fpath := append(path[:len(path):len(path)], sf.Name)
+		key := fieldPathKey{offset: offset + sf.Offset, typ: sf.Type}
+		if _, ok := paths[key]; !ok {
+			paths[key] = strings.Join(fpath, ".")
+		}
+		if sf.Type.Kind() == reflect.Struct {
+			addTypeFieldPaths(paths, sf.Type, offset+sf.Offset, fpath)
+		}
+	}
+}
+
+// findFieldPath recurses into the exported struct fields of v to find the field with the passed address and type.
+func findFieldPath(v reflect.Value, addr uintptr, typ reflect.Type, path []string) ([]string, bool) {
+	v = reflectValueElem(v)
+	if v.Kind() != reflect.Struct || !v.CanAddr() {
+		return nil, false
+	}
+	for i := 0; i < v.NumField(); i++ {
+		sf := v.Type().Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := v.Field(i)
+		fpath := append(path[:len(path):len(path)], sf.Name)
+		if fv.Type() == typ && fv.UnsafeAddr() == addr {
+			return fpath, true
+		}
+		if found, ok := findFieldPath(fv, addr, typ, fpath); ok {
+			return found, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,47 @@
+package inreq
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldPath(t *testing.T) {
+	type Inner struct {
+		Val  int
+		Next *Inner
+	}
+
+	type DataType struct {
+		First  Inner
+		Second Inner
+		Ptr    *Inner
+	}
+
+	data := &DataType{Ptr: &Inner{Next: &Inner{}}}
+	root := reflect.ValueOf(data)
+
+	tests := []struct {
+		field reflect.Value
+		want  string
+	}{
+		{field: root.Elem(), want: "inreq.DataType"},
+		{field: root.Elem().Field(0), want: "First"},
+		{field: root.Elem().Field(0).Field(0), want: "First.Val"},
+		{field: root.Elem().Field(1).Field(0), want: "Second.Val"},
+		{field: root.Elem().Field(2).Elem().Field(0), want: "Ptr.Val"},
+		{field: root.Elem().Field(2).Elem().Field(1).Elem().Field(0), want: "Ptr.Next.Val"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			path, ok := fieldPath(root, tt.field)
+			require.True(t, ok)
+			require.Equal(t, tt.want, path)
+		})
+	}
+
+	_, ok := fieldPath(root, reflect.ValueOf(&Inner{}).Elem())
+	require.False(t, ok)
+}
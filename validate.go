@@ -0,0 +1,351 @@
+package inreq
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Validation rules, which can be set as tag options. They are evaluated in this order right after the field
+// value is resolved.
+// Tag options always need a value, so boolean rules must be set like "email=true". As tag options are separated by
+// commas, parameters (like "pattern") cannot contain them.
+// Invalid parameters (like "min=abc") or rules which don't support the field type return an error wrapping
+// ErrInvalidConfiguration. They are checked once for each field type.
+const (
+	ValidateNonEmpty = "nonempty" // "nonempty=true": value must not be empty (or zero).
+	ValidateLen      = "len"      // "len=N": string length or slice/map item count must be exactly N.
+	ValidateMin      = "min"      // "min=N": minimum numeric value, string length or slice/map item count.
+	ValidateMax      = "max"      // "max=N": maximum numeric value, string length or slice/map item count.
+	ValidatePattern  = "pattern"  // "pattern=regexp": string must match the regular expression.
+	ValidateOneOf    = "oneof"    // "oneof=a|b|c": value must be one of the "|"-separated values.
+	ValidateEmail    = "email"    // "email=true": string must be a valid e-mail address.
+	ValidateUUID     = "uuid"     // "uuid=true": string must be a valid UUID.
+)
+
+var validationRules = []string{
+	ValidateNonEmpty,
+	ValidateLen,
+	ValidateMin,
+	ValidateMax,
+	ValidatePattern,
+	ValidateOneOf,
+	ValidateEmail,
+	ValidateUUID,
+}
+
+var (
+	durationType      = reflect.TypeOf(time.Duration(0))
+	uuidRegexp        = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	validatePatterns  sync.Map // cache of compiled "pattern" regular expressions.
+	validateParams    sync.Map // cache of validateParamKey to the error checking the parameter.
+	errValidateNoType = fmt.Errorf("rule not supported for type")
+)
+
+// validateParamKey is a validation rule parameter for a field type.
+type validateParamKey struct {
+	typ   reflect.Type
+	rule  string
+	param string
+}
+
+// validateField checks the validation rules set in the tag options against the resolved field value.
+func validateField(ctx DecodeContext, field reflect.Value, tag *Tag) error {
+	for _, rule := range validationRules {
+		param, ok := tag.Options.Get(rule)
+		if !ok {
+			continue
+		}
+		value := fieldValue(field)
+		err := checkValidateParam(rule, param, value.Type())
+		var valid bool
+		if err == nil {
+			valid, err = validateRule(rule, param, value)
+		}
+		if err != nil {
+			return fmt.Errorf("%w: invalid '%s' validation rule for field '%s': %w", ErrInvalidConfiguration, rule,
+				ctx.FieldPath(field), err)
+		}
+		if !valid {
+			ve := ValidationError{
+				Operation: tag.Operation,
+				FieldName: ctx.FieldPath(field),
+				TagName:   tag.Name,
+				Rule:      rule,
+			}
+			if !validateRuleIsBool(rule) {
+				ve.Param = param
+			}
+			return ve
+		}
+	}
+	return nil
+}
+
+func validateRuleIsBool(rule string) bool {
+	switch rule {
+	case ValidateNonEmpty, ValidateEmail, ValidateUUID:
+		return true
+	}
+	return false
+}
+
+// checkValidateParam checks if the rule parameter is valid for the field type, using a cache.
+func checkValidateParam(rule string, param string, typ reflect.Type) error {
+	key := validateParamKey{typ: typ, rule: rule, param: param}
+	if err, ok := validateParams.Load(key); ok {
+		return err.(errorValue).error
+	}
+	err := validateParam(rule, param, typ)
+	validateParams.Store(key, errorValue{err})
+	return err
+}
+
+// errorValue allows storing nil errors in a sync.Map.
+type errorValue struct {
+	error
+}
+
+// validateParam checks if the rule parameter is valid for the field type.
+func validateParam(rule string, param string, typ reflect.Type) error {
+	if validateRuleIsBool(rule) {
+		enabled, err := strconv.ParseBool(param)
+		if err != nil || !enabled {
+			return err
+		}
+	}
+
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+
+	switch rule {
+	case ValidateLen:
+		if _, ok := validateLength(reflect.New(typ).Elem()); !ok {
+			return errValidateNoType
+		}
+		_, err := strconv.Atoi(param)
+		return err
+	case ValidateMin, ValidateMax:
+		// the zero value is enough to check the parameter type.
+		_, err := validateCompare(reflect.New(typ).Elem(), param)
+		return err
+	case ValidatePattern:
+		if _, err := validatePattern(param); err != nil {
+			return err
+		}
+	}
+
+	switch rule {
+	case ValidatePattern, ValidateEmail, ValidateUUID:
+		if !validateElementType(typ, false) {
+			return errValidateNoType
+		}
+	case ValidateOneOf:
+		if !validateElementType(typ, true) {
+			return errValidateNoType
+		}
+	}
+	return nil
+}
+
+// validateRule checks a single validation rule.
+func validateRule(rule string, param string, v reflect.Value) (bool, error) {
+	if validateRuleIsBool(rule) {
+		enabled, err := strconv.ParseBool(param)
+		if err != nil || !enabled {
+			return true, err
+		}
+	}
+
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return rule != ValidateNonEmpty, nil
+		}
+		v = v.Elem()
+	}
+
+	switch rule {
+	case ValidateNonEmpty:
+		switch v.Kind() {
+		case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+			return v.Len() > 0, nil
+		}
+		return !v.IsZero(), nil
+	case ValidateLen:
+		n, ok := validateLength(v)
+		if !ok {
+			return false, errValidateNoType
+		}
+		p, err := strconv.Atoi(param)
+		if err != nil {
+			return false, err
+		}
+		return n == p, nil
+	case ValidateMin, ValidateMax:
+		cmp, err := validateCompare(v, param)
+		if err != nil {
+			return false, err
+		}
+		if rule == ValidateMin {
+			return cmp >= 0, nil
+		}
+		return cmp <= 0, nil
+	case ValidatePattern:
+		re, err := validatePattern(param)
+		if err != nil {
+			return false, err
+		}
+		return validateElements(v, false, func(s string) bool {
+			return re.MatchString(s)
+		})
+	case ValidateOneOf:
+		values := strings.Split(param, "|")
+		return validateElements(v, true, func(s string) bool {
+			for _, value := range values {
+				if s == value {
+					return true
+				}
+			}
+			return false
+		})
+	case ValidateEmail:
+		return validateElements(v, false, func(s string) bool {
+			addr, err := mail.ParseAddress(s)
+			return err == nil && addr.Name == "" && addr.Address == s
+		})
+	case ValidateUUID:
+		return validateElements(v, false, uuidRegexp.MatchString)
+	}
+	return true, nil
+}
+
+// validateLength returns the string length or slice/map item count.
+func validateLength(v reflect.Value) (int, bool) {
+	switch v.Kind() {
+	case reflect.String:
+		return utf8.RuneCountInString(v.String()), true
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len(), true
+	}
+	return 0, false
+}
+
+// validateCompare compares the value with the parameter, returning -1, 0 or 1. Strings, slices and maps are
+// compared using their length.
+func validateCompare(v reflect.Value, param string) (int, error) {
+	if n, ok := validateLength(v); ok {
+		p, err := strconv.Atoi(param)
+		if err != nil {
+			return 0, err
+		}
+		return compare(n, p), nil
+	}
+
+	if v.Type() == durationType {
+		p, err := time.ParseDuration(param)
+		if err != nil {
+			return 0, err
+		}
+		return compare(time.Duration(v.Int()), p), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		p, err := strconv.ParseInt(param, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compare(v.Int(), p), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		p, err := strconv.ParseUint(param, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compare(v.Uint(), p), nil
+	case reflect.Float32, reflect.Float64:
+		p, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return 0, err
+		}
+		return compare(v.Float(), p), nil
+	}
+	return 0, errValidateNoType
+}
+
+// validateElements calls check for the string value, or for each item if it is a slice or array.
+// If allowScalar is true, numbers and booleans are also checked using their string representation.
+func validateElements(v reflect.Value, allowScalar bool, check func(s string) bool) (bool, error) {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			valid, err := validateElements(reflectValueElem(v.Index(i)), allowScalar, check)
+			if err != nil || !valid {
+				return valid, err
+			}
+		}
+		return true, nil
+	case reflect.String:
+		return check(v.String()), nil
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if allowScalar {
+			return check(fmt.Sprint(v.Interface())), nil
+		}
+	}
+	return false, errValidateNoType
+}
+
+// validateElementType returns whether validateElements supports the type.
+func validateElementType(typ reflect.Type, allowScalar bool) bool {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch typ.Kind() {
+	case reflect.Slice, reflect.Array:
+		return validateElementType(typ.Elem(), allowScalar)
+	case reflect.String:
+		return true
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return allowScalar
+	}
+	return false
+}
+
+// validatePattern returns the compiled regular expression, using a cache.
+func validatePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := validatePatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	validatePatterns.Store(pattern, re)
+	return re, nil
+}
+
+type ordered interface {
+	~int | ~int64 | ~uint64 | ~float64
+}
+
+func compare[T ordered](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
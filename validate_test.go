@@ -0,0 +1,281 @@
+package inreq
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		body     string
+		data     interface{}
+		wantRule string
+		wantErr  bool
+	}{
+		{
+			name:  "min max int",
+			query: "val=5",
+			data: &struct {
+				Val int `inreq:"query,min=1,max=10"`
+			}{},
+		},
+		{
+			name:  "min int error",
+			query: "val=0",
+			data: &struct {
+				Val int `inreq:"query,min=1,max=10"`
+			}{},
+			wantRule: ValidateMin,
+		},
+		{
+			name:  "max float error",
+			query: "val=10.5",
+			data: &struct {
+				Val float64 `inreq:"query,max=10"`
+			}{},
+			wantRule: ValidateMax,
+		},
+		{
+			name:  "max duration error",
+			query: "val=20",
+			data: &struct {
+				Val time.Duration `inreq:"query,max=10ns"`
+			}{},
+			wantRule: ValidateMax,
+		},
+		{
+			name:  "string length",
+			query: "val=abc",
+			data: &struct {
+				Val string `inreq:"query,len=3,min=2,max=3"`
+			}{},
+		},
+		{
+			name:  "string length error",
+			query: "val=abcd",
+			data: &struct {
+				Val string `inreq:"query,len=3"`
+			}{},
+			wantRule: ValidateLen,
+		},
+		{
+			name:  "slice length error",
+			query: "val=a&val=b&val=c",
+			data: &struct {
+				Val []string `inreq:"query,max=2"`
+			}{},
+			wantRule: ValidateMax,
+		},
+		{
+			name:  "pattern",
+			query: "val=ab12",
+			data: &struct {
+				Val string `inreq:"query,pattern=^[a-z]+[0-9]+$"`
+			}{},
+		},
+		{
+			name:  "pattern error",
+			query: "val=12ab",
+			data: &struct {
+				Val string `inreq:"query,pattern=^[a-z]+[0-9]+$"`
+			}{},
+			wantRule: ValidatePattern,
+		},
+		{
+			name:  "oneof",
+			query: "val=b",
+			data: &struct {
+				Val string `inreq:"query,oneof=a|b|c"`
+			}{},
+		},
+		{
+			name:  "oneof int",
+			query: "val=2",
+			data: &struct {
+				Val int `inreq:"query,oneof=1|2"`
+			}{},
+		},
+		{
+			name:  "oneof slice error",
+			query: "val=a&val=d",
+			data: &struct {
+				Val []string `inreq:"query,oneof=a|b|c"`
+			}{},
+			wantRule: ValidateOneOf,
+		},
+		{
+			name:  "email",
+			query: "val=user@example.com",
+			data: &struct {
+				Val string `inreq:"query,email=true"`
+			}{},
+		},
+		{
+			name:  "email error",
+			query: "val=Name+<user@example.com>",
+			data: &struct {
+				Val string `inreq:"query,email=true"`
+			}{},
+			wantRule: ValidateEmail,
+		},
+		{
+			name:  "uuid",
+			query: "val=2b8a8c3e-0b1c-4a5e-9f3a-6f1b2c3d4e5f",
+			data: &struct {
+				Val string `inreq:"query,uuid=true"`
+			}{},
+		},
+		{
+			name:  "uuid error",
+			query: "val=2b8a8c3e",
+			data: &struct {
+				Val string `inreq:"query,uuid=true"`
+			}{},
+			wantRule: ValidateUUID,
+		},
+		{
+			name:  "nonempty error",
+			query: "val=",
+			data: &struct {
+				Val string `inreq:"query,nonempty=true"`
+			}{},
+			wantRule: ValidateNonEmpty,
+		},
+		{
+			name:  "nonempty disabled",
+			query: "val=",
+			data: &struct {
+				Val string `inreq:"query,nonempty=false"`
+			}{},
+		},
+		{
+			name: "body max error",
+			body: `{"Val":"x"}`,
+			data: &struct {
+				Val string `inreq:"body,max=5"`
+			}{},
+			wantRule: ValidateMax,
+		},
+		{
+			name:  "not found is not validated",
+			query: "",
+			data: &struct {
+				Val string `inreq:"query,required=false,nonempty=true"`
+			}{},
+		},
+		{
+			name:  "invalid rule parameter",
+			query: "val=5",
+			data: &struct {
+				Val int `inreq:"query,min=abc"`
+			}{},
+			wantErr: true,
+		},
+		{
+			name:  "rule not supported for type",
+			query: "val=true",
+			data: &struct {
+				Val bool `inreq:"query,pattern=true"`
+			}{},
+			wantErr: true,
+		},
+		{
+			name:  "invalid boolean rule",
+			query: "val=a@example.com",
+			data: &struct {
+				Val string `inreq:"query,email=yes"`
+			}{},
+			wantErr: true,
+		},
+		{
+			name:  "invalid rule parameter for valid value",
+			query: "val=5",
+			data: &struct {
+				Val []string `inreq:"query,max=5s"`
+			}{},
+			wantErr: true,
+		},
+	}
+
+	for i := range tests {
+		tt := tests[i]
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/?"+tt.query, strings.NewReader(tt.body))
+
+			err := Decode(r, tt.data)
+			if tt.wantRule != "" {
+				var verr ValidationError
+				require.ErrorAs(t, err, &verr)
+				require.Equal(t, tt.wantRule, verr.Rule)
+				require.Equal(t, "Val", verr.FieldName)
+			} else if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidConfiguration)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDecodeValidateError(t *testing.T) {
+	type Inner struct {
+		Val string `inreq:"header,name=X-Val,oneof=a|b"`
+	}
+
+	type DataType struct {
+		Inner Inner `inreq:"recurse"`
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/", nil)
+	r.Header.Set("X-Val", "c")
+
+	_, err := DecodeType[DataType](r)
+	var verr ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.Equal(t, ValidationError{
+		Operation: OperationHeader,
+		FieldName: "Inner.Val",
+		TagName:   "X-Val",
+		Rule:      ValidateOneOf,
+		Param:     "a|b",
+	}, verr)
+}
+
+func TestDecodeValidateInvalidConfiguration(t *testing.T) {
+	type DataType struct {
+		Val   int `inreq:"query,min=abc"`
+		Other int `inreq:"query"`
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/?val=5&other=x", nil)
+
+	// configuration errors are returned even when collecting errors.
+	err := Decode(r, &DataType{}, WithCollectErrors(true))
+	require.ErrorIs(t, err, ErrInvalidConfiguration)
+	var derrs DecodeErrors
+	require.False(t, errors.As(err, &derrs))
+}
+
+func TestDecodeValidateMapTags(t *testing.T) {
+	type DataType struct {
+		Val int
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/?val=20", nil)
+
+	_, err := DecodeType[DataType](r, WithMapTags(map[string]any{
+		"Val": "query,max=10",
+	}))
+	var verr ValidationError
+	require.ErrorAs(t, err, &verr)
+	require.Equal(t, ValidateMax, verr.Rule)
+	require.Equal(t, "val", verr.TagName)
+}